@@ -0,0 +1,135 @@
+package mold
+
+import "reflect"
+
+// implementsType reports whether t implements the interface type u, for
+// any mix of live and static types. For an interface that is not a method
+// set, t must also belong to its type set.
+func implementsType(t, u Type) bool {
+	if u.Kind() != reflect.Interface {
+		panic("non-interface type passed to Implements")
+	}
+	if !u.IsMethodSet() {
+		return satisfies(t, u)
+	}
+	return implements(t, u)
+}
+
+// assignableTo reports whether a value of type v is assignable to a
+// variable of type t, as defined at https://golang.org/ref/spec#Assignability.
+// The rules for untyped constants and nil do not apply since they only
+// concern values.
+func assignableTo(v, t Type) bool {
+	if v == invalidType || t == invalidType {
+		return false
+	}
+	if identical(v, t) {
+		return true
+	}
+	if isTypeParam(v) || isTypeParam(t) {
+		return false
+	}
+
+	// identical underlying types, unless both types are named
+	vu, tu := underlying(v), underlying(t)
+	if (!isNamed(v) || !isNamed(t)) && identical(vu, tu) {
+		return true
+	}
+
+	// a bidirectional channel is assignable to a directional channel
+	if vu.Kind() == reflect.Chan && tu.Kind() == reflect.Chan && vu.ChanDir() == reflect.BothDir {
+		if (!isNamed(v) || !isNamed(t)) && identical(vu.Elem(), tu.Elem()) {
+			return true
+		}
+	}
+
+	// a value of any type that implements an interface is assignable to it
+	if t.Kind() == reflect.Interface && t.IsMethodSet() {
+		return implements(v, t)
+	}
+	return false
+}
+
+// convertibleTo reports whether a value of type v can be converted to type
+// t, as defined at https://golang.org/ref/spec#Conversions. The rules for
+// constants do not apply since they only concern values.
+func convertibleTo(v, t Type) bool {
+	if assignableTo(v, t) {
+		return true
+	}
+	if v == invalidType || t == invalidType || isTypeParam(v) || isTypeParam(t) {
+		return false
+	}
+
+	// identical underlying types, ignoring struct tags
+	vu, tu := underlying(v), underlying(t)
+	if identicalIgnoreTags(vu, tu) {
+		return true
+	}
+
+	// unnamed pointers to types with identical underlying types
+	if vu.Kind() == reflect.Ptr && tu.Kind() == reflect.Ptr && !isNamed(v) && !isNamed(t) {
+		if identicalIgnoreTags(underlying(vu.Elem()), underlying(tu.Elem())) {
+			return true
+		}
+	}
+
+	vk, tk := vu.Kind(), tu.Kind()
+	switch {
+	case isNumber(vk) && isNumber(tk):
+		return true
+	case isComplex(vk) && isComplex(tk):
+		return true
+	case tk == reflect.String:
+		// integers are converted to the string holding the code point, and
+		// slices of bytes or runes to the string holding their contents
+		return isInteger(vk) || isBytesOrRunes(vu)
+	case vk == reflect.String:
+		return isBytesOrRunes(tu)
+	case vk == reflect.Slice && tk == reflect.Array:
+		return identical(vu.Elem(), tu.Elem())
+	case vk == reflect.Slice && tk == reflect.Ptr:
+		elem := underlying(tu.Elem())
+		return elem.Kind() == reflect.Array && identical(vu.Elem(), elem.Elem())
+	case vk == reflect.UnsafePointer:
+		// as permitted by package unsafe
+		return tk == reflect.Ptr || tk == reflect.Uintptr
+	case tk == reflect.UnsafePointer:
+		return vk == reflect.Ptr || vk == reflect.Uintptr
+	}
+	return false
+}
+
+// isNamed reports whether t is a named type, including predeclared types
+// such as int and type parameters
+func isNamed(t Type) bool {
+	return t.Name() != ""
+}
+
+func isTypeParam(t Type) bool {
+	_, ok := t.(*staticTypeParam)
+	return ok
+}
+
+func isInteger(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Uintptr
+}
+
+// isNumber reports whether k is an integer or floating point kind
+func isNumber(k reflect.Kind) bool {
+	return isInteger(k) || k == reflect.Float32 || k == reflect.Float64
+}
+
+func isComplex(k reflect.Kind) bool {
+	return k == reflect.Complex64 || k == reflect.Complex128
+}
+
+// isBytesOrRunes reports whether t is a slice whose elements have
+// underlying type byte or rune
+func isBytesOrRunes(t Type) bool {
+	if t.Kind() != reflect.Slice {
+		return false
+	}
+	k := underlying(t.Elem()).Kind()
+	return k == reflect.Uint8 || k == reflect.Int32
+}
@@ -4,18 +4,26 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"reflect"
+	"sort"
 	"strconv"
 )
 
-func makeSkeleton(expr ast.Expr, name, pkg string) Type {
+// makeSkeleton creates an unpopulated type for a type expression, or
+// returns nil if the expression does not denote a type
+func makeSkeleton(expr ast.Expr, name string, pkg *pkgInfo) Type {
 	st := staticType{name: name, pkg: pkg}
 	switch expr := expr.(type) {
 	case *ast.ParenExpr:
 		return makeSkeleton(expr.X, name, pkg)
 	case *ast.Ident:
-		return &staticAlias{st: st, expr: expr}
+		return &staticDefined{st: st, expr: expr}
 	case *ast.SelectorExpr:
-		panic("imported symbols not implemented")
+		return &staticDefined{st: st, expr: expr}
+	case *ast.IndexExpr:
+		return &staticDefined{st: st, expr: expr}
+	case *ast.IndexListExpr:
+		return &staticDefined{st: st, expr: expr}
 	case *ast.StarExpr:
 		return &staticPtr{staticType: st, expr: expr}
 	case *ast.MapType:
@@ -27,26 +35,93 @@ func makeSkeleton(expr ast.Expr, name, pkg string) Type {
 			return &staticArray{staticType: st, expr: expr}
 		}
 	case *ast.StructType:
-		return &staticStruct{staticType: st, expr: expr}
+		return &staticStruct{staticType: st, layout: new(structLayout), expr: expr}
 	case *ast.InterfaceType:
 		return &staticInterface{staticType: st, expr: expr}
+	case *ast.FuncType:
+		return &staticFunc{staticType: st, expr: expr}
+	case *ast.ChanType:
+		return &staticChan{staticType: st, expr: expr}
 	default:
-		panic(fmt.Sprintf("unexpected %T in type expression", expr))
+		return nil
 	}
 }
 
 type builder struct {
-	pkg     string
-	symbols map[string]Type // user-defined types + builtins
-	named   map[string]Type // user-defined types only
-	unnamed []Type
+	pkg       *pkgInfo
+	dir       string // directory containing the sources, for resolving imports
+	imported  bool   // whether this package was loaded as a dependency
+	loader    *loader
+	symbols   map[string]Type // user-defined types + builtins
+	named     map[string]Type // user-defined types only
+	unnamed   []Type
+	aliases   map[string]bool        // names declared as aliases
+	decls     map[string]*typeDecl   // all type declarations, by name
+	methods   map[string][]*funcDecl // method declarations, by receiver base type name
+	consts    map[string]*constDecl  // constant declarations, by name
+	constList []*constDecl           // constant declarations, in declaration order
+	funcDecls []*funcDecl            // top-level function declarations, in declaration order
+	vars      map[string]*varDecl    // variable declarations, by name
+	declared  map[string]bool        // names declared in the package block
+	pending   []*typeDecl            // declarations looked up but not yet populated
+	file      *sourceFile            // file containing the declaration being populated
+	scope     map[string]Type        // type parameters of the declaration being populated
 }
 
-func newBuilder(pkg string) *builder {
+// typeDecl is a package-level type declaration, or an instantiation of
+// a generic type declaration with particular type arguments
+type typeDecl struct {
+	spec    *ast.TypeSpec
+	file    *sourceFile
+	builder *builder
+	typ     Type   // nil until the declaration is first looked up
+	args    []Type // type arguments, for instantiations only
+	depth   int    // number of enclosing instantiations, for instantiations only
+	cyclic  bool   // whether an instantiation cycle has been reported
+	state   declState
+}
+
+// typeArgs returns the types that the type parameters of a declaration
+// stand for: the type arguments for an instantiation, or the type
+// parameters themselves for a generic declaration
+func (decl *typeDecl) typeArgs() []Type {
+	if decl.args != nil {
+		return decl.args
+	}
+	var params []Type
+	if named, ok := decl.typ.(interface{ base() *staticType }); ok {
+		for _, p := range named.base().typeParams {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// funcDecl is a package-level function or method declaration
+type funcDecl struct {
+	decl *ast.FuncDecl
+	file *sourceFile
+}
+
+type declState int
+
+const (
+	declPending declState = iota
+	declPopulating
+	declPopulated
+)
+
+func newBuilder(pkg *pkgInfo) *builder {
 	b := builder{
-		pkg:     pkg,
-		symbols: make(map[string]Type),
-		named:   make(map[string]Type),
+		pkg:      pkg,
+		symbols:  make(map[string]Type),
+		named:    make(map[string]Type),
+		aliases:  make(map[string]bool),
+		decls:    make(map[string]*typeDecl),
+		methods:  make(map[string][]*funcDecl),
+		consts:   make(map[string]*constDecl),
+		vars:     make(map[string]*varDecl),
+		declared: make(map[string]bool),
 	}
 
 	b.symbols["bool"] = TypeOf(true)
@@ -65,6 +140,7 @@ func newBuilder(pkg string) *builder {
 	b.symbols["uint16"] = TypeOf(uint16(0))
 	b.symbols["uint32"] = TypeOf(uint32(0))
 	b.symbols["uint64"] = TypeOf(uint64(0))
+	b.symbols["uintptr"] = TypeOf(uintptr(0))
 
 	b.symbols["float32"] = TypeOf(float32(0))
 	b.symbols["float64"] = TypeOf(float64(0))
@@ -74,48 +150,482 @@ func newBuilder(pkg string) *builder {
 
 	var err error
 	b.symbols["error"] = TypeOf(&err).Elem()
+
+	var any interface{}
+	b.symbols["any"] = TypeOf(&any).Elem()
+	b.symbols["comparable"] = comparableType
 	return &b
 }
 
+// errorf records a problem with the expression at pos
+func (b *builder) errorf(pos token.Pos, category ErrorCategory, ident string, format string, args ...interface{}) {
+	b.loader.errors = append(b.loader.errors, &Error{
+		Pos:      b.loader.fset.Position(pos),
+		Ident:    ident,
+		Category: category,
+		Msg:      fmt.Sprintf(format, args...),
+	})
+}
+
+// resolve finds or constructs the type denoted by a type expression. If
+// the expression is invalid then an error is recorded and the invalid type
+// is returned, so that construction can continue and report further errors.
 func (b *builder) resolve(expr ast.Expr) Type {
 	switch expr := expr.(type) {
 	case *ast.ParenExpr:
 		return b.resolve(expr.X)
-	case *ast.Ident:
-		if t, found := b.symbols[expr.Name]; found {
-			return t
+	case *ast.Ident, *ast.SelectorExpr:
+		if ident, ok := expr.(*ast.Ident); ok {
+			// a type parameter may be bound to a generic type within its own
+			// declaration, where the generic type stands for its instantiation
+			// with its own type parameters, as in "Box[Node[T]]"
+			if t, found := b.scope[ident.Name]; found {
+				return t
+			}
+		}
+		t := b.resolveName(expr)
+		if t != invalidType && t.NumTypeParam() > 0 {
+			b.errorf(expr.Pos(), InvalidDeclaration, t.Name(),
+				"cannot use generic type %s without instantiation", t)
+			return invalidType
 		}
-		panic(fmt.Sprintf("unknown type: %s", expr.Name))
+		return t
+	case *ast.IndexExpr:
+		return b.instantiate(expr, expr.X, []ast.Expr{expr.Index})
+	case *ast.IndexListExpr:
+		return b.instantiate(expr, expr.X, expr.Indices)
 	default:
 		t := makeSkeleton(expr, "", b.pkg)
+		if t == nil {
+			b.errorf(expr.Pos(), UnsupportedExpression, "", "unexpected %T in type expression", expr)
+			return invalidType
+		}
 		b.populate(t)
 		b.unnamed = append(b.unnamed, t)
 		return t
 	}
 }
 
-func (b *builder) add(decl ast.Decl) {
-	if decl, ok := decl.(*ast.GenDecl); ok && decl.Tok == token.TYPE {
-		for _, spec := range decl.Specs {
-			spec := spec.(*ast.TypeSpec)
-			name := spec.Name.Name
-			t := makeSkeleton(spec.Type, name, b.pkg)
-			b.symbols[name] = t
-			b.named[name] = t
+// resolveName finds the type denoted by an identifier or a qualified
+// identifier, which may be a generic type that has not been instantiated
+func (b *builder) resolveName(expr ast.Expr) Type {
+	switch expr := expr.(type) {
+	case *ast.ParenExpr:
+		return b.resolveName(expr.X)
+	case *ast.Ident:
+		if expr.Name == "_" {
+			b.errorf(expr.Pos(), InvalidDeclaration, expr.Name, "cannot use _ as a type")
+			return invalidType
+		}
+		if t, found := b.scope[expr.Name]; found {
+			return t
 		}
+		if t, found := b.lookup(expr.Name); found {
+			return t
+		}
+		pkgs, failed := b.dotImports()
+		if ast.IsExported(expr.Name) {
+			for _, pkg := range pkgs {
+				if t, found := pkg.lookup(expr.Name); found {
+					return t
+				}
+			}
+		}
+		if failed {
+			return invalidType // may be declared by the failed import, which has already been reported
+		}
+		b.errorf(expr.Pos(), UnknownIdentifier, expr.Name, "unknown type: %s", expr.Name)
+		return invalidType
+	case *ast.SelectorExpr:
+		x, ok := expr.X.(*ast.Ident)
+		if !ok {
+			b.errorf(expr.Pos(), UnsupportedExpression, "",
+				"unexpected %T in qualified identifier", expr.X)
+			return invalidType
+		}
+		pkg, found := b.importedPackage(x.Name)
+		if !found {
+			b.errorf(x.Pos(), UnknownIdentifier, x.Name, "unknown package: %s", x.Name)
+			return invalidType
+		}
+		if pkg == nil {
+			return invalidType // import failed, which has already been reported
+		}
+		if t, found := pkg.lookup(expr.Sel.Name); found {
+			return t
+		}
+		ident := x.Name + "." + expr.Sel.Name
+		b.errorf(expr.Sel.Pos(), UnknownIdentifier, ident, "unknown type: %s", ident)
+		return invalidType
+	default:
+		return b.resolve(expr)
 	}
 }
 
-func (b *builder) build() {
-	for _, t := range b.named {
-		b.populate(t)
+// lookup finds a type declared in this package or a builtin type,
+// constructing the type first if this is the first time it was looked up
+func (b *builder) lookup(name string) (Type, bool) {
+	decl, found := b.decls[name]
+	if !found {
+		t, found := b.symbols[name]
+		return t, found
+	}
+	if decl.spec.Assign.IsValid() {
+		return b.lookupAlias(decl), true
+	}
+	if decl.typ == nil {
+		decl.typ = makeSkeleton(decl.spec.Type, name, b.pkg)
+		if decl.typ == nil {
+			b.errorf(decl.spec.Type.Pos(), UnsupportedExpression, name,
+				"unexpected %T in declaration of %s", decl.spec.Type, name)
+			decl.typ = invalidType
+			return decl.typ, true
+		}
+		b.symbols[name] = decl.typ
+		b.named[name] = decl.typ
+		b.pending = append(b.pending, decl)
+		b.loader.decls[decl.typ] = decl
+		if decl.spec.TypeParams != nil {
+			st := decl.typ.(interface{ base() *staticType }).base()
+			st.typeParams = makeTypeParams(decl.spec.TypeParams, b.pkg)
+		}
+	}
+	return decl.typ, true
+}
+
+// lookupAlias resolves an alias declaration such as "type A = B" to the
+// type it denotes, so that the alias and its target are the same Type
+func (b *builder) lookupAlias(decl *typeDecl) Type {
+	name := decl.spec.Name.Name
+	switch decl.state {
+	case declPopulated:
+		return decl.typ
+	case declPopulating:
+		b.errorf(decl.spec.Name.Pos(), InvalidDeclaration, name, "invalid recursive type alias %s", name)
+		decl.typ = invalidType
+		return decl.typ
+	}
+
+	decl.state = declPopulating
+	if decl.spec.TypeParams != nil {
+		b.errorf(decl.spec.TypeParams.Pos(), UnsupportedExpression, name,
+			"generic type alias %s is not supported", name)
+		decl.typ = invalidType
+	} else {
+		file, scope := b.file, b.scope
+		b.file, b.scope = decl.file, nil
+		typ := b.resolve(decl.spec.Type)
+		b.file, b.scope = file, scope
+		if decl.typ == nil {
+			decl.typ = typ // unless a cycle was reported while resolving
+		}
+	}
+	decl.state = declPopulated
+	b.symbols[name] = decl.typ
+	b.named[name] = decl.typ
+	b.aliases[name] = true
+	return decl.typ
+}
+
+func (b *builder) addFile(file *ast.File) {
+	f := &sourceFile{imports: file.Imports}
+	for _, decl := range file.Decls {
+		b.add(decl, f)
+	}
+}
+
+func (b *builder) add(decl ast.Decl, file *sourceFile) {
+	switch decl := decl.(type) {
+	case *ast.GenDecl:
+		if decl.Tok == token.CONST || decl.Tok == token.VAR {
+			b.addValues(decl, file)
+		}
+		if decl.Tok == token.TYPE {
+			for _, spec := range decl.Specs {
+				spec := spec.(*ast.TypeSpec)
+				if spec.Name.Name == "_" || !b.declare(spec.Name) {
+					continue // a blank declaration declares nothing
+				}
+				b.decls[spec.Name.Name] = &typeDecl{spec: spec, file: file, builder: b}
+			}
+		}
+	case *ast.FuncDecl:
+		if decl.Recv == nil {
+			// init functions and blank functions cannot be referred to
+			if name := decl.Name.Name; name != "init" && name != "_" && b.declare(decl.Name) {
+				b.funcDecls = append(b.funcDecls, &funcDecl{decl: decl, file: file})
+			}
+		} else if len(decl.Recv.List) == 1 {
+			if name, _ := receiverBase(decl.Recv.List[0].Type); name != "" {
+				b.methods[name] = append(b.methods[name], &funcDecl{decl: decl, file: file})
+			}
+		}
+	}
+}
+
+// declare records a name declared in the package block, reporting false
+// and an error if the name has already been declared
+func (b *builder) declare(ident *ast.Ident) bool {
+	if b.declared[ident.Name] {
+		b.errorf(ident.Pos(), InvalidDeclaration, ident.Name,
+			"%s redeclared in this block", ident.Name)
+		return false
+	}
+	b.declared[ident.Name] = true
+	return true
+}
+
+// receiverScope maps the type parameter names in a receiver such as
+// "*Page[E]" to the types that the type parameters of the declaration
+// stand for, since a method may use different names from the declaration
+func receiverScope(expr ast.Expr, types []Type) map[string]Type {
+	var indices []ast.Expr
+	for indices == nil {
+		switch e := expr.(type) {
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			indices = []ast.Expr{e.Index}
+		case *ast.IndexListExpr:
+			indices = e.Indices
+		default:
+			return nil
+		}
+	}
+
+	scope := make(map[string]Type)
+	for i, index := range indices {
+		if ident, ok := index.(*ast.Ident); ok && i < len(types) && ident.Name != "_" {
+			scope[ident.Name] = types[i]
+		}
+	}
+	return scope
+}
+
+// receiverBase finds the name of the type on which a method is declared,
+// and whether the receiver is a pointer
+func receiverBase(expr ast.Expr) (name string, ptr bool) {
+	for {
+		switch e := expr.(type) {
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.StarExpr:
+			if ptr {
+				return "", false // a pointer to a pointer is not a valid receiver
+			}
+			ptr = true
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name, ptr
+		default:
+			return "", false
+		}
+	}
+}
+
+// build constructs every type declared in the package, together with
+// the types they refer to in imported packages. All problems found along
+// the way are returned as an ErrorList.
+func (b *builder) build() error {
+	for name := range b.decls {
+		b.lookup(name)
+	}
+	b.loader.build()
+	b.validate()
+	b.loader.errors.Sort()
+	return b.loader.errors.Err()
+}
+
+// populatePending populates the types that have been looked up since the
+// last call, and reports whether there were any
+func (b *builder) populatePending() bool {
+	if len(b.pending) == 0 {
+		return false
+	}
+	for len(b.pending) > 0 {
+		decl := b.pending[0]
+		b.pending = b.pending[1:]
+		if decl.state == declPending {
+			b.populateDecl(decl)
+		}
+	}
+	return true
+}
+
+// makeTypeParams creates the type parameters for a generic declaration.
+// Their constraints are resolved when the declaration is populated.
+func makeTypeParams(fields *ast.FieldList, pkg *pkgInfo) []*staticTypeParam {
+	var params []*staticTypeParam
+	for _, f := range fields.List {
+		for _, ident := range f.Names {
+			params = append(params, &staticTypeParam{
+				staticType: staticType{name: ident.Name, pkg: pkg},
+				index:      len(params),
+			})
+		}
+	}
+	return params
+}
+
+// typeParamScope maps the names of the type parameters in a declaration
+// to the types they stand for
+func typeParamScope(fields *ast.FieldList, types []Type) map[string]Type {
+	if fields == nil {
+		return nil
+	}
+	scope := make(map[string]Type)
+	i := 0
+	for _, f := range fields.List {
+		for _, ident := range f.Names {
+			if i < len(types) {
+				scope[ident.Name] = types[i]
+			}
+			i++
+		}
+	}
+	return scope
+}
+
+func (b *builder) populateDecl(decl *typeDecl) {
+	file, scope, current := b.file, b.scope, b.loader.current
+	b.file = decl.file
+	b.scope = typeParamScope(decl.spec.TypeParams, decl.typeArgs())
+	b.loader.current = decl
+	decl.state = declPopulating
+	if decl.spec.TypeParams != nil && decl.args == nil {
+		params := decl.typ.(interface{ base() *staticType }).base().typeParams
+		b.populateTypeParams(decl.spec.TypeParams, params)
+	}
+	b.populate(decl.typ)
+	decl.state = declPopulated
+	b.populateMethods(decl)
+	b.file, b.scope, b.loader.current = file, scope, current
+}
+
+// populateTypeParams resolves the constraints of type parameters, which
+// must already be in scope since constraints may refer to them
+func (b *builder) populateTypeParams(fields *ast.FieldList, params []*staticTypeParam) {
+	i := 0
+	for _, f := range fields.List {
+		constraint := b.resolveConstraint(f.Type)
+		for range f.Names {
+			params[i].constraint = constraint
+			i++
+		}
+	}
+}
+
+// resolveConstraint resolves a type parameter constraint. A constraint
+// such as "~int | ~string" or "int" that is not an interface is shorthand
+// for an interface containing just that element.
+func (b *builder) resolveConstraint(expr ast.Expr) Type {
+	switch expr.(type) {
+	case *ast.BinaryExpr, *ast.UnaryExpr:
+	default:
+		t := b.resolve(expr)
+		if t == invalidType || !b.complete(t) || t.Kind() == reflect.Interface {
+			return t
+		}
+	}
+	return b.resolve(&ast.InterfaceType{
+		Interface: expr.Pos(),
+		Methods:   &ast.FieldList{List: []*ast.Field{{Type: expr}}},
+	})
+}
+
+// populateMethods adds the methods declared on a type. Signatures in the
+// package being loaded are resolved right away so that problems can be
+// reported, but signatures in imported packages are resolved on first use,
+// since resolving them would otherwise pull in the transitive closure of
+// every package they mention.
+func (b *builder) populateMethods(decl *typeDecl) {
+	named, ok := decl.typ.(interface{ base() *staticType })
+	if !ok {
+		return
+	}
+	st := named.base()
+	var fds []*funcDecl
+	for _, name := range b.receiverNames(decl.spec.Name.Name) {
+		fds = append(fds, b.methods[name]...)
+	}
+	for _, fd := range fds {
+		fd := fd
+		_, ptr := receiverBase(fd.decl.Recv.List[0].Type)
+		m := &method{
+			name:    fd.decl.Name.Name,
+			pkgPath: b.qualifier(fd.decl.Name.Name),
+			recv:    decl.typ,
+			ptrRecv: ptr,
+			pos:     b.loader.fset.Position(fd.decl.Name.Pos()),
+			resolve: func() Type {
+				file, scope := b.file, b.scope
+				b.file = fd.file
+				b.scope = receiverScope(fd.decl.Recv.List[0].Type, decl.typeArgs())
+				t := b.resolve(fd.decl.Type)
+				b.file, b.scope = file, scope
+				// populate the types reached from the signature, which does
+				// nothing while a declaration is being populated
+				b.loader.build()
+				return t
+			},
+		}
+		if !b.imported {
+			m.signature()
+		}
+		st.methods = append(st.methods, m)
 	}
+	sort.Slice(st.methods, func(i, j int) bool {
+		return st.methods[i].name < st.methods[j].name
+	})
+}
+
+// receiverNames returns the names under which methods may be declared on
+// a type: its own name and the names of any aliases that denote it
+func (b *builder) receiverNames(name string) []string {
+	names := []string{name}
+	seen := map[string]bool{name: true}
+	for i := 0; i < len(names); i++ {
+		for alias, decl := range b.decls {
+			if !decl.spec.Assign.IsValid() || seen[alias] {
+				continue
+			}
+			if ident, ok := decl.spec.Type.(*ast.Ident); ok && ident.Name == names[i] {
+				names = append(names, alias)
+				seen[alias] = true
+			}
+		}
+	}
+	return names
+}
+
+// complete populates t right away if it is a declared type that has not
+// been populated yet, so that its contents can be inspected. It reports
+// false if t is already being populated, which means that t depends on
+// itself.
+func (b *builder) complete(t Type) bool {
+	decl, found := b.loader.decls[t]
+	if !found {
+		return true // unnamed types are populated as soon as they are created
+	}
+	switch decl.state {
+	case declPopulating:
+		return false
+	case declPending:
+		decl.builder.populateDecl(decl)
+	}
+	return true
 }
 
 func (b *builder) populate(t Type) {
 	switch t := t.(type) {
-	case *staticAlias:
-		b.populateAlias(t)
+	case *staticDefined:
+		b.populateDefined(t)
 	case *staticPtr:
 		b.populatePtr(t)
 	case *staticArray:
@@ -128,13 +638,27 @@ func (b *builder) populate(t Type) {
 		b.populateStruct(t)
 	case *staticInterface:
 		b.populateInterface(t)
+	case *staticFunc:
+		b.populateFunc(t)
+	case *staticChan:
+		b.populateChan(t)
+	case *staticInvalid:
+		// nothing to do, the error has already been reported
 	default:
 		panic(fmt.Sprintf("unable to populate %T", t))
 	}
 }
 
-func (b *builder) populateAlias(t *staticAlias) {
-	t.Type = b.resolve(t.expr)
+// populateDefined populates a type declared in terms of another named
+// type, as in "type Celsius float64". The new type takes on the underlying
+// type of the other type but none of its methods.
+func (b *builder) populateDefined(t *staticDefined) {
+	target := b.resolve(t.expr)
+	if !b.complete(target) {
+		b.errorf(t.expr.Pos(), InvalidDeclaration, t.st.name, "invalid recursive type %s", t.st.name)
+		target = invalidType
+	}
+	t.Type = underlying(target)
 	t.expr = nil
 }
 
@@ -146,21 +670,15 @@ func (b *builder) populatePtr(t *staticPtr) {
 func (b *builder) populateArray(t *staticArray) {
 	t.elem = b.resolve(t.expr.Elt)
 
-	length, ok := t.expr.Len.(*ast.BasicLit)
-	if !ok {
-		panic(fmt.Sprintf("can only parse arrays with literal ints for length, got %T",
-			t.expr.Len))
-	}
-	if length.Kind != token.INT {
-		panic(fmt.Sprintf("can only parse arrays with literal ints for length, got %v",
-			length.Kind))
-	}
-
-	var err error
-	t.length, err = strconv.Atoi(length.Value)
-	if err != nil {
-		panic(fmt.Sprintf("error parsing array length: %v", err))
+	if _, ok := t.expr.Len.(*ast.Ellipsis); ok {
+		b.errorf(t.expr.Len.Pos(), InvalidArrayLength, "",
+			"invalid use of [...] array (outside a composite literal)")
+		t.expr = nil
+		return
 	}
+	e := evaluator{b: b, iota: -1, category: InvalidArrayLength}
+	v, typ := e.eval(t.expr.Len)
+	t.length = e.arrayLength(t.expr.Len, v, typ)
 	t.expr = nil
 }
 
@@ -180,32 +698,275 @@ func (b *builder) populateStruct(t *staticStruct) {
 		r := b.resolve(f.Type)
 		var tag StructTag
 		if f.Tag != nil {
-			tag = StructTag(f.Tag.Value)
+			value, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				b.errorf(f.Tag.Pos(), InvalidDeclaration, "", "invalid struct tag %s", f.Tag.Value)
+			}
+			tag = StructTag(value)
 		}
 		if f.Names == nil {
 			// anonymous field
+			name := embeddedName(f.Type)
 			t.fields = append(t.fields, StructField{
-				Name:      r.Name(),
+				Name:      name,
+				PkgPath:   b.qualifier(name),
 				Type:      r,
 				Tag:       tag,
+				Index:     []int{len(t.fields)},
 				Anonymous: true,
 			})
 		} else {
 			// symbols field
 			for _, ident := range f.Names {
 				t.fields = append(t.fields, StructField{
-					Name: ident.Name,
-					Type: r,
-					Tag:  tag,
+					Name:    ident.Name,
+					PkgPath: b.qualifier(ident.Name),
+					Type:    r,
+					Tag:     tag,
+					Index:   []int{len(t.fields)},
 				})
 			}
 		}
 	}
-	// TODO: populate PkgPath, Offset, Index in each StructField
+	// offsets are computed once all types have been populated
+	b.loader.structs = append(b.loader.structs, t)
 	t.expr = nil
 }
 
+// embeddedName finds the name of an embedded field, which is the name of
+// its type without any package qualifier, pointer or type arguments
+func embeddedName(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.SelectorExpr:
+			return e.Sel.Name
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
 func (b *builder) populateInterface(t *staticInterface) {
-	// TODO: extract methods
+	explicit := make(map[string]bool)
+	byName := make(map[string]*method)
+	add := func(m *method, pos token.Pos, isExplicit bool) {
+		if prev, found := byName[m.name]; found {
+			// methods from embedded interfaces may overlap if their signatures
+			// are identical, but explicit methods must be unique
+			if (isExplicit && explicit[m.name]) || !identical(prev.signature(), m.signature()) {
+				b.errorf(pos, InvalidDeclaration, m.name, "duplicate method %s", m.name)
+			}
+			return
+		}
+		byName[m.name] = m
+		explicit[m.name] = isExplicit
+		t.methods = append(t.methods, m)
+	}
+
+	// the type set of the interface is the intersection of the type sets
+	// of its elements
+	restrict := func(terms []Term, elem string) {
+		t.elems = append(t.elems, elem)
+		if t.restricted {
+			t.terms = intersectTerms(t.terms, terms)
+		} else {
+			t.terms = terms
+			t.restricted = true
+		}
+	}
+
+	for _, f := range t.expr.Methods.List {
+		if len(f.Names) > 0 {
+			sig := b.resolve(f.Type)
+			for _, ident := range f.Names {
+				add(&method{
+					name:    ident.Name,
+					pkgPath: b.qualifier(ident.Name),
+					typ:     sig,
+					pos:     b.loader.fset.Position(ident.Pos()),
+				}, ident.Pos(), true)
+			}
+			continue
+		}
+
+		switch expr := f.Type.(type) {
+		case *ast.BinaryExpr, *ast.UnaryExpr:
+			// union or approximation element, such as "~int | ~string"
+			if terms, all := b.resolveUnion(expr); !all {
+				restrict(terms, formatTerms(terms))
+			}
+			continue
+		}
+
+		e := b.resolve(f.Type)
+		if e == invalidType {
+			continue
+		}
+		if !b.complete(e) {
+			b.errorf(f.Type.Pos(), InvalidDeclaration, e.Name(),
+				"invalid recursive embedding of interface %s", e.Name())
+			continue
+		}
+		if e.Kind() != reflect.Interface {
+			// a single type is a type set with one element
+			restrict([]Term{{Type: e}}, e.String())
+			continue
+		}
+
+		// embedded interface
+		for _, m := range interfaceMethods(e) {
+			add(m, f.Type.Pos(), false)
+		}
+		if terms, restricted := typeSet(e); restricted {
+			restrict(terms, e.String())
+		}
+		if isComparableConstraint(e) {
+			t.comparable = true
+		}
+	}
+
+	// comparable is itself an element of the intersection, so it removes
+	// the terms whose types are not comparable
+	if t.comparable && t.restricted {
+		var terms []Term
+		for _, term := range t.terms {
+			if !b.complete(term.Type) || isComparable(term.Type) {
+				terms = append(terms, term)
+			}
+		}
+		t.terms = terms
+	}
+
+	sort.Slice(t.methods, func(i, j int) bool {
+		return t.methods[i].name < t.methods[j].name
+	})
+	t.expr = nil
+}
+
+// resolveUnion resolves the terms of a union element within an interface,
+// such as "~int | ~string". It reports true if the union contains every
+// type, which happens when one of its terms is an unrestricted interface.
+func (b *builder) resolveUnion(expr ast.Expr) (terms []Term, all bool) {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return b.resolveUnion(e.X)
+	case *ast.BinaryExpr:
+		if e.Op == token.OR {
+			x, xall := b.resolveUnion(e.X)
+			y, yall := b.resolveUnion(e.Y)
+			return append(x, y...), xall || yall
+		}
+	case *ast.UnaryExpr:
+		if e.Op == token.TILDE {
+			t := b.resolve(e.X)
+			if t == invalidType || !b.completeTerm(e.X, t) {
+				return nil, false
+			}
+			if t.Kind() == reflect.Interface {
+				b.errorf(e.Pos(), InvalidDeclaration, t.Name(),
+					"invalid use of ~ (%s is an interface)", t)
+				return nil, false
+			}
+			if !identical(t, underlying(t)) {
+				b.errorf(e.Pos(), InvalidDeclaration, t.Name(),
+					"invalid use of ~ (underlying type of %s is %s)", t, underlying(t))
+				return nil, false
+			}
+			return []Term{{Tilde: true, Type: t}}, false
+		}
+	default:
+		t := b.resolve(e)
+		if t == invalidType || !b.completeTerm(e, t) {
+			return nil, false
+		}
+		if t.Kind() != reflect.Interface {
+			return []Term{{Type: t}}, false
+		}
+		if len(interfaceMethods(t)) > 0 || isComparableConstraint(t) {
+			b.errorf(e.Pos(), InvalidDeclaration, t.Name(),
+				"cannot use %s in union (interface contains methods)", t)
+			return nil, false
+		}
+		terms, restricted := typeSet(t)
+		return terms, !restricted
+	}
+	b.errorf(expr.Pos(), UnsupportedExpression, "", "unexpected %T in interface element", expr)
+	return nil, false
+}
+
+// completeTerm completes the type of a term in a union, reporting an error
+// if the term refers to the interface being declared
+func (b *builder) completeTerm(expr ast.Expr, t Type) bool {
+	if !b.complete(t) {
+		b.errorf(expr.Pos(), InvalidDeclaration, t.Name(), "invalid recursive type %s", t.Name())
+		return false
+	}
+	return true
+}
+
+// qualifier returns the package path that qualifies an identifier, which
+// is empty for exported identifiers
+func (b *builder) qualifier(name string) string {
+	if token.IsExported(name) {
+		return ""
+	}
+	return b.pkg.path
+}
+
+func (b *builder) populateChan(t *staticChan) {
+	switch t.expr.Dir {
+	case ast.SEND:
+		t.dir = reflect.SendDir
+	case ast.RECV:
+		t.dir = reflect.RecvDir
+	default:
+		t.dir = reflect.BothDir
+	}
+	t.elem = b.resolve(t.expr.Value)
 	t.expr = nil
 }
+
+func (b *builder) populateFunc(t *staticFunc) {
+	t.in = b.resolveParams(t.expr.Params)
+	t.out = b.resolveParams(t.expr.Results)
+	if n := len(t.expr.Params.List); n > 0 {
+		_, t.variadic = t.expr.Params.List[n-1].Type.(*ast.Ellipsis)
+	}
+	t.expr = nil
+}
+
+// resolveParams resolves the types in a parameter or result list, with one
+// entry per parameter. A final "...T" parameter is resolved to []T.
+func (b *builder) resolveParams(fields *ast.FieldList) []Type {
+	if fields == nil {
+		return nil
+	}
+	var types []Type
+	for _, f := range fields.List {
+		var r Type
+		if ellipsis, ok := f.Type.(*ast.Ellipsis); ok {
+			r = &staticSlice{elem: b.resolve(ellipsis.Elt)}
+			b.unnamed = append(b.unnamed, r)
+		} else {
+			r = b.resolve(f.Type)
+		}
+		n := len(f.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			types = append(types, r)
+		}
+	}
+	return types
+}
@@ -4,13 +4,41 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/alexflint/go-arg"
 	"github.com/alexflint/go-mold"
 	"github.com/kr/pretty"
 )
 
+type layoutArgs struct {
+	Path string `arg:"positional,required" help:"source file or package directory"`
+	Type string `arg:"positional,required"`
+	Arch string `help:"target architecture, such as amd64 or 386"`
+}
+
 func main() {
+	// "load-mold layout PATH TYPE" prints the memory layout of a struct
+	// type, and otherwise "load-mold FILE [TYPE]" shows the types in a file
+	if len(os.Args) > 1 && os.Args[1] == "layout" {
+		var args layoutArgs
+		p, err := arg.NewParser(arg.Config{Program: "load-mold layout"}, &args)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := p.Parse(os.Args[2:]); err != nil {
+			if err == arg.ErrHelp {
+				p.WriteHelp(os.Stdout)
+				os.Exit(0)
+			}
+			p.Fail(err.Error())
+		}
+		layout(&args)
+		return
+	}
+
 	var args struct {
 		File string `arg:"positional,required"`
 		Type string `arg:"positional"`
@@ -35,3 +63,49 @@ func main() {
 		pretty.Println(types[args.Type])
 	}
 }
+
+func layout(args *layoutArgs) {
+	var opts []mold.Option
+	if args.Arch != "" {
+		opts = append(opts, mold.WithArch(args.Arch))
+	}
+
+	load := mold.LoadFile
+	if st, err := os.Stat(args.Path); err == nil && st.IsDir() {
+		load = mold.LoadDir
+	}
+	types, err := load(args.Path, opts...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	t, found := types[args.Type]
+	if !found {
+		log.Fatalf("%s not found in %s", args.Type, args.Path)
+	}
+	if t.Kind() != reflect.Struct {
+		log.Fatalf("%s is a %s, not a struct", args.Type, t.Kind())
+	}
+
+	l := mold.Layout(t)
+	fmt.Printf("%s: size %d, align %d, padding %d\n", t, l.Size, l.Align, l.Padding)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "offset\tsize\talign\t\tfield")
+	for _, f := range l.Fields {
+		fmt.Fprintf(w, "%d\t%d\t%d\t\t%s %s\n", f.Offset, f.Size, f.Align, f.Name, f.Type)
+		if f.Padding > 0 {
+			fmt.Fprintf(w, "%d\t%d\t\t\t(padding)\n", f.Offset+f.Size, f.Padding)
+		}
+	}
+	w.Flush()
+
+	if l.OptimalSize < l.Size {
+		var names []string
+		for _, i := range l.OptimalOrder {
+			names = append(names, l.Fields[i].Name)
+		}
+		fmt.Printf("\nreordering the fields would reduce the size to %d:\n  %s\n",
+			l.OptimalSize, strings.Join(names, ", "))
+	}
+}
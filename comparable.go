@@ -0,0 +1,75 @@
+package mold
+
+import (
+	"errors"
+	"reflect"
+)
+
+// ComparableError reports why values of type t cannot be compared with ==,
+// naming the component of t that prevents it, as in "Person.Children is a
+// slice". It returns nil if t is comparable.
+func ComparableError(t Type) error {
+	if reason := incomparable(t, t.String(), make(map[Type]bool)); reason != "" {
+		return errors.New(reason)
+	}
+	return nil
+}
+
+// isComparable reports whether values of type t can be compared with ==
+func isComparable(t Type) bool {
+	return incomparable(t, "", make(map[Type]bool)) == ""
+}
+
+// incomparable returns a description of why values of type t cannot be
+// compared with ==, or an empty string if they can be. The path describes
+// how t was reached from the type being checked. A struct or array that
+// contains itself is only possible in an invalid declaration, and is
+// treated as comparable the second time it is reached.
+func incomparable(t Type, path string, seen map[Type]bool) string {
+	switch t.Kind() {
+	case reflect.Invalid:
+		return path + " is an invalid type"
+	case reflect.Slice:
+		return path + " is a slice"
+	case reflect.Map:
+		return path + " is a map"
+	case reflect.Func:
+		return path + " is a func"
+	case reflect.Array:
+		if seen[t] {
+			return ""
+		}
+		seen[t] = true
+		return incomparable(t.Elem(), path+"[i]", seen)
+	case reflect.Struct:
+		if seen[t] {
+			return ""
+		}
+		seen[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if reason := incomparable(f.Type, path+"."+f.Name, seen); reason != "" {
+				return reason
+			}
+		}
+	case reflect.Interface:
+		// interfaces are comparable, although comparing two interface values
+		// panics at run time if their dynamic types are not, but a type
+		// parameter is only comparable if its constraint guarantees it
+		p, ok := t.(*staticTypeParam)
+		if !ok || isComparableConstraint(p) {
+			return ""
+		}
+		terms, restricted := typeSet(p)
+		if !restricted {
+			return path + " is a type parameter that is not constrained to be comparable"
+		}
+		for _, term := range terms {
+			if !isComparable(term.Type) {
+				return path + " is a type parameter that permits " + term.Type.String() +
+					", which is not comparable"
+			}
+		}
+	}
+	return ""
+}
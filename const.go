@@ -0,0 +1,694 @@
+package mold
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"reflect"
+)
+
+// constDecl is a package-level constant declaration. Constants are
+// evaluated on first use, since they may refer to constants and types
+// declared later in the package.
+type constDecl struct {
+	name  *ast.Ident
+	typ   ast.Expr // declared type, or nil if the constant is untyped
+	expr  ast.Expr // value, which may be repeated from an earlier spec in the group
+	iota  int      // index of the spec within its group
+	group *ast.GenDecl
+	doc   string
+	file  *sourceFile
+	value constant.Value // nil until evaluated
+	t     Type           // type of the constant, or nil if it is untyped
+	state declState
+}
+
+// varDecl is a package-level variable declaration. Variables are only
+// recorded so that expressions such as "len(x)" can be evaluated when x
+// is an array.
+type varDecl struct {
+	typ   ast.Expr // declared type, or nil if the type is inferred
+	value ast.Expr // initial value, or nil if there is none
+	file  *sourceFile
+}
+
+// addValues records the constants or variables declared by a "const" or
+// "var" declaration. Within a group of constants, a spec with no values
+// repeats the type and values of the previous spec.
+func (b *builder) addValues(decl *ast.GenDecl, file *sourceFile) {
+	var typ ast.Expr
+	var values []ast.Expr
+	for i, spec := range decl.Specs {
+		spec := spec.(*ast.ValueSpec)
+		if decl.Tok == token.VAR {
+			for j, ident := range spec.Names {
+				if ident.Name == "_" || !b.declare(ident) {
+					continue
+				}
+				v := &varDecl{typ: spec.Type, file: file}
+				if j < len(spec.Values) {
+					v.value = spec.Values[j]
+				}
+				b.vars[ident.Name] = v
+			}
+			continue
+		}
+
+		if spec.Values != nil {
+			typ, values = spec.Type, spec.Values
+		}
+		for j, ident := range spec.Names {
+			if ident.Name == "_" || !b.declare(ident) {
+				continue
+			}
+			c := &constDecl{name: ident, typ: typ, iota: i, group: decl, file: file}
+			if j < len(values) {
+				c.expr = values[j]
+			}
+			// a declaration of a single constant may be documented either
+			// before the const keyword or before the spec
+			switch {
+			case spec.Doc != nil:
+				c.doc = spec.Doc.Text()
+			case !decl.Lparen.IsValid() && decl.Doc != nil:
+				c.doc = decl.Doc.Text()
+			}
+			b.consts[ident.Name] = c
+			b.constList = append(b.constList, c)
+		}
+	}
+}
+
+// evalConst evaluates a constant declaration. The value is Unknown if the
+// constant could not be evaluated, in which case an error has been
+// recorded.
+func (b *builder) evalConst(c *constDecl) (constant.Value, Type) {
+	switch c.state {
+	case declPopulated:
+		return c.value, c.t
+	case declPopulating:
+		b.errorf(c.name.Pos(), InvalidConstant, c.name.Name,
+			"initialization cycle: %s refers to itself", c.name.Name)
+		return constant.MakeUnknown(), nil
+	}
+
+	c.state = declPopulating
+	file, scope := b.file, b.scope
+	b.file, b.scope = c.file, nil
+
+	e := evaluator{b: b, iota: c.iota, category: InvalidConstant}
+	var v constant.Value
+	var t Type
+	if c.expr == nil {
+		e.errorf(c.name.Pos(), c.name.Name, "missing init expr for %s", c.name.Name)
+		v = constant.MakeUnknown()
+	} else {
+		v, t = e.eval(c.expr)
+	}
+	if c.typ != nil {
+		t = b.resolve(c.typ)
+		v = e.represent(c.name, v, t)
+	}
+
+	b.file, b.scope = file, scope
+	c.value, c.t, c.state = v, t, declPopulated
+	return v, t
+}
+
+// evaluator evaluates constant expressions, such as array lengths and the
+// values of constant declarations
+type evaluator struct {
+	b        *builder
+	iota     int           // value of iota, or -1 outside a constant declaration
+	category ErrorCategory // category of the errors reported
+}
+
+func (e *evaluator) errorf(pos token.Pos, ident string, format string, args ...interface{}) {
+	e.b.errorf(pos, e.category, ident, format, args...)
+}
+
+// eval evaluates a constant expression, returning its value and its type,
+// which is nil for untyped constants. If the expression is not a valid
+// constant expression then an error is recorded and the value is Unknown.
+func (e *evaluator) eval(expr ast.Expr) (constant.Value, Type) {
+	unknown := constant.MakeUnknown()
+	switch expr := expr.(type) {
+	case *ast.ParenExpr:
+		return e.eval(expr.X)
+
+	case *ast.BasicLit:
+		v := constant.MakeFromLiteral(expr.Value, expr.Kind, 0)
+		if v.Kind() == constant.Unknown {
+			e.errorf(expr.Pos(), "", "invalid literal %s", expr.Value)
+		}
+		return v, nil
+
+	case *ast.Ident:
+		if c, found := e.b.consts[expr.Name]; found {
+			return e.b.evalConst(c)
+		}
+		switch expr.Name {
+		case "iota":
+			if e.iota < 0 {
+				e.errorf(expr.Pos(), expr.Name, "cannot use iota outside constant declaration")
+				return unknown, nil
+			}
+			return constant.MakeInt64(int64(e.iota)), nil
+		case "true", "false":
+			return constant.MakeBool(expr.Name == "true"), nil
+		}
+		pkgs, failed := e.b.dotImports()
+		if ast.IsExported(expr.Name) {
+			for _, pkg := range pkgs {
+				if c, found := pkg.consts[expr.Name]; found {
+					return pkg.evalConst(c)
+				}
+			}
+		}
+		if failed {
+			return unknown, nil // may be declared by the failed import, which has already been reported
+		}
+		e.errorf(expr.Pos(), expr.Name, "undefined constant: %s", expr.Name)
+		return unknown, nil
+
+	case *ast.SelectorExpr:
+		x, ok := expr.X.(*ast.Ident)
+		if !ok {
+			e.errorf(expr.Pos(), "", "unexpected %T in qualified identifier", expr.X)
+			return unknown, nil
+		}
+		pkg, found := e.b.importedPackage(x.Name)
+		if !found {
+			e.errorf(x.Pos(), x.Name, "unknown package: %s", x.Name)
+			return unknown, nil
+		}
+		if pkg == nil {
+			return unknown, nil // import failed, which has already been reported
+		}
+		if c, found := pkg.consts[expr.Sel.Name]; found && ast.IsExported(expr.Sel.Name) {
+			return pkg.evalConst(c)
+		}
+		ident := x.Name + "." + expr.Sel.Name
+		e.errorf(expr.Sel.Pos(), ident, "undefined constant: %s", ident)
+		return unknown, nil
+
+	case *ast.UnaryExpr:
+		return e.unary(expr)
+
+	case *ast.BinaryExpr:
+		return e.binary(expr)
+
+	case *ast.CallExpr:
+		return e.call(expr)
+	}
+	e.errorf(expr.Pos(), "", "%s is not constant", types.ExprString(expr))
+	return unknown, nil
+}
+
+func (e *evaluator) unary(expr *ast.UnaryExpr) (constant.Value, Type) {
+	x, t := e.eval(expr.X)
+	if x.Kind() == constant.Unknown {
+		return x, t
+	}
+
+	ok := false
+	prec := uint(0)
+	switch expr.Op {
+	case token.ADD, token.SUB:
+		ok = isNumericValue(x)
+	case token.XOR:
+		ok = x.Kind() == constant.Int
+		if t != nil && e.b.complete(t) {
+			// the complement of an unsigned value has the width of its type
+			if bits, signed := e.b.pkg.sizes.intBits(t.Kind()); !signed {
+				prec = uint(bits)
+			}
+		}
+	case token.NOT:
+		ok = x.Kind() == constant.Bool
+	}
+	if !ok {
+		e.errorf(expr.Pos(), "", "invalid operation: operator %s not defined on %s",
+			expr.Op, types.ExprString(expr.X))
+		return constant.MakeUnknown(), nil
+	}
+	return e.represent(expr, constant.UnaryOp(expr.Op, x, prec), t), t
+}
+
+func (e *evaluator) binary(expr *ast.BinaryExpr) (constant.Value, Type) {
+	unknown := constant.MakeUnknown()
+	x, xt := e.eval(expr.X)
+	y, yt := e.eval(expr.Y)
+	if x.Kind() == constant.Unknown || y.Kind() == constant.Unknown {
+		return unknown, nil
+	}
+
+	if expr.Op == token.SHL || expr.Op == token.SHR {
+		x = constant.ToInt(x)
+		n, ok := constant.Uint64Val(constant.ToInt(y))
+		if !ok || constant.ToInt(y).Kind() != constant.Int {
+			e.errorf(expr.Y.Pos(), "", "invalid shift count %s", types.ExprString(expr.Y))
+			return unknown, nil
+		}
+		if x.Kind() != constant.Int {
+			e.errorf(expr.X.Pos(), "", "invalid operation: shifted operand %s must be integer",
+				types.ExprString(expr.X))
+			return unknown, nil
+		}
+		if n > 10000 {
+			e.errorf(expr.Y.Pos(), "", "shift count %d too large", n)
+			return unknown, nil
+		}
+		return e.represent(expr, constant.Shift(x, expr.Op, uint(n)), xt), xt
+	}
+
+	t := xt
+	if t == nil {
+		t = yt
+	} else if yt != nil && !identical(xt, yt) {
+		e.errorf(expr.OpPos, "", "invalid operation: %s (mismatched types %s and %s)",
+			types.ExprString(expr), xt, yt)
+		return unknown, nil
+	}
+
+	ok := false
+	switch expr.Op {
+	case token.EQL, token.NEQ:
+		ok = x.Kind() == y.Kind() || isNumericValue(x) && isNumericValue(y)
+	case token.LSS, token.LEQ, token.GTR, token.GEQ:
+		ok = x.Kind() == constant.String && y.Kind() == constant.String ||
+			isNumericValue(x) && isNumericValue(y) && x.Kind() != constant.Complex && y.Kind() != constant.Complex
+	case token.ADD:
+		ok = x.Kind() == constant.String && y.Kind() == constant.String || isNumericValue(x) && isNumericValue(y)
+	case token.SUB, token.MUL, token.QUO:
+		ok = isNumericValue(x) && isNumericValue(y)
+	case token.REM, token.AND, token.OR, token.XOR, token.AND_NOT:
+		ok = x.Kind() == constant.Int && y.Kind() == constant.Int
+	case token.LAND, token.LOR:
+		ok = x.Kind() == constant.Bool && y.Kind() == constant.Bool
+	}
+	if !ok {
+		e.errorf(expr.OpPos, "", "invalid operation: operator %s not defined on %s",
+			expr.Op, types.ExprString(expr))
+		return unknown, nil
+	}
+
+	switch expr.Op {
+	case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+		return constant.MakeBool(constant.Compare(x, expr.Op, y)), nil
+	case token.QUO, token.REM:
+		if constant.Sign(y) == 0 {
+			e.errorf(expr.Y.Pos(), "", "invalid operation: division by zero")
+			return unknown, nil
+		}
+	}
+
+	op := expr.Op
+	if op == token.QUO && x.Kind() == constant.Int && y.Kind() == constant.Int {
+		op = token.QUO_ASSIGN // integer division
+	}
+	return e.represent(expr, constant.BinaryOp(x, op, y), t), t
+}
+
+func (e *evaluator) call(expr *ast.CallExpr) (constant.Value, Type) {
+	unknown := constant.MakeUnknown()
+	if fun, ok := expr.Fun.(*ast.Ident); ok && !e.b.declared[fun.Name] {
+		switch fun.Name {
+		case "complex", "real", "imag", "min", "max":
+			e.errorf(expr.Pos(), "", "%s is not supported in constant expressions", fun.Name)
+			return unknown, nil
+		}
+	}
+	if len(expr.Args) != 1 || expr.Ellipsis.IsValid() {
+		e.errorf(expr.Pos(), "", "%s is not constant", types.ExprString(expr))
+		return unknown, nil
+	}
+	arg := expr.Args[0]
+
+	switch fun := expr.Fun.(type) {
+	case *ast.Ident:
+		if _, declared := e.b.decls[fun.Name]; declared {
+			break // a type named like a builtin function
+		}
+		switch fun.Name {
+		case "len", "cap":
+			return e.length(expr, arg)
+		}
+	case *ast.SelectorExpr:
+		x, ok := fun.X.(*ast.Ident)
+		if !ok {
+			break
+		}
+		// unsafe.Pointer is a type, so "unsafe.Pointer(x)" is a conversion
+		pkg, _ := e.b.importedPackage(x.Name)
+		if pkg != nil && pkg.pkg.path == "unsafe" && fun.Sel.Name != "Pointer" {
+			switch fun.Sel.Name {
+			case "Sizeof", "Alignof":
+			default:
+				e.errorf(expr.Pos(), "", "%s is not supported in constant expressions", types.ExprString(fun))
+				return unknown, nil
+			}
+			t := e.typeOf(arg)
+			if t == nil {
+				return unknown, nil
+			}
+			if !e.b.completeLayout(t, make(map[Type]bool)) {
+				e.errorf(arg.Pos(), "", "invalid recursive type %s", t)
+				return unknown, nil
+			}
+			s := e.b.pkg.sizes
+			switch fun.Sel.Name {
+			case "Sizeof":
+				return constant.MakeInt64(s.sizeof(t)), TypeOf(uintptr(0))
+			default:
+				return constant.MakeInt64(s.alignof(t)), TypeOf(uintptr(0))
+			}
+		}
+	}
+
+	// conversion, such as "Weekday(3)" or "uint8(x)"
+	t := e.b.resolve(expr.Fun)
+	if t == invalidType {
+		return unknown, nil
+	}
+	v, _ := e.eval(arg)
+	if v.Kind() == constant.Int && e.b.complete(t) && t.Kind() == reflect.String {
+		// an integer converts to the UTF-8 encoding of the code point
+		if n, ok := constant.Int64Val(v); ok {
+			return constant.MakeString(string(rune(n))), t
+		}
+	}
+	return e.represent(expr, v, t), t
+}
+
+// arrayLength checks that a constant is a valid array length, which must
+// be a non-negative integer representable by an int
+func (e *evaluator) arrayLength(expr ast.Expr, v constant.Value, t Type) int {
+	if v.Kind() == constant.Unknown {
+		return 0 // already reported
+	}
+	if t != nil && e.b.complete(t) {
+		if bits, _ := e.b.pkg.sizes.intBits(t.Kind()); bits == 0 {
+			e.errorf(expr.Pos(), "", "array length %s (constant of type %s) must be integer",
+				types.ExprString(expr), t)
+			return 0
+		}
+	}
+	n := constant.ToInt(v)
+	if n.Kind() != constant.Int {
+		e.errorf(expr.Pos(), "", "array length %s (constant %s) must be integer", types.ExprString(expr), v)
+		return 0
+	}
+	bits, _ := e.b.pkg.sizes.intBits(reflect.Int)
+	length, exact := constant.Int64Val(n)
+	if !exact || length < 0 || bits < 64 && length >= 1<<(bits-1) {
+		e.errorf(expr.Pos(), "", "invalid array length %s (constant %s)", types.ExprString(expr), n)
+		return 0
+	}
+	return int(length)
+}
+
+// length evaluates "len(x)" or "cap(x)", which is constant if x is a
+// constant string or an array
+func (e *evaluator) length(expr *ast.CallExpr, arg ast.Expr) (constant.Value, Type) {
+	name := expr.Fun.(*ast.Ident).Name
+	if ident, ok := arg.(*ast.Ident); ok {
+		if _, found := e.b.consts[ident.Name]; !found {
+			if t := e.typeOf(arg); t != nil {
+				if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Array {
+					t = t.Elem()
+				}
+				if t.Kind() == reflect.Array {
+					return constant.MakeInt64(int64(t.Len())), TypeOf(0)
+				}
+				e.errorf(arg.Pos(), ident.Name, "%s(%s) is not constant", name, ident.Name)
+			}
+			return constant.MakeUnknown(), nil
+		}
+	}
+	if lit, ok := arg.(*ast.CompositeLit); ok {
+		if t := e.typeOf(lit); t != nil && t.Kind() == reflect.Array {
+			return constant.MakeInt64(int64(t.Len())), TypeOf(0)
+		}
+	}
+
+	v, _ := e.eval(arg)
+	switch {
+	case v.Kind() == constant.String && name == "len":
+		return constant.MakeInt64(int64(len(constant.StringVal(v)))), TypeOf(0)
+	case v.Kind() != constant.Unknown:
+		e.errorf(arg.Pos(), "", "invalid argument: %s for built-in %s", types.ExprString(arg), name)
+	}
+	return constant.MakeUnknown(), nil
+}
+
+// typeOf finds the type of an expression that appears as the argument of
+// a builtin function such as len or unsafe.Sizeof. It supports composite
+// literals, conversions, constants and package-level variables, and
+// records an error and returns nil for other expressions.
+func (e *evaluator) typeOf(expr ast.Expr) Type {
+	switch expr := expr.(type) {
+	case *ast.ParenExpr:
+		return e.typeOf(expr.X)
+
+	case *ast.CompositeLit:
+		if array, ok := expr.Type.(*ast.ArrayType); ok {
+			if _, ok := array.Len.(*ast.Ellipsis); ok {
+				// an array literal such as "[...]int{1, 2, 3}"
+				n, ok := e.countElements(expr)
+				if !ok {
+					return nil
+				}
+				elem := e.b.resolve(array.Elt)
+				if elem == invalidType {
+					return nil
+				}
+				return &staticArray{staticType: staticType{pkg: e.b.pkg}, elem: elem, length: n}
+			}
+		}
+		if expr.Type == nil {
+			break
+		}
+		if t := e.b.resolve(expr.Type); t != invalidType {
+			return t
+		}
+		return nil
+
+	case *ast.CallExpr:
+		// the type of a conversion such as "unsafe.Pointer(nil)" does not
+		// depend on whether its argument is a constant
+		if t := e.conversionType(expr.Fun); t != nil {
+			return t
+		}
+		if _, t := e.eval(expr); t != nil {
+			return t
+		}
+		return nil
+
+	case *ast.Ident:
+		if v, found := e.b.vars[expr.Name]; found {
+			file, scope := e.b.file, e.b.scope
+			e.b.file, e.b.scope = v.file, nil
+			defer func() { e.b.file, e.b.scope = file, scope }()
+			if v.typ != nil {
+				if t := e.b.resolve(v.typ); t != invalidType {
+					return t
+				}
+				return nil
+			}
+			if v.value != nil {
+				return e.typeOf(v.value)
+			}
+			break
+		}
+		v, t := e.eval(expr)
+		if v.Kind() == constant.Unknown {
+			return nil
+		}
+		if t == nil {
+			t = defaultType(v)
+		}
+		return t
+	}
+	e.errorf(expr.Pos(), "", "cannot determine the type of %s", types.ExprString(expr))
+	return nil
+}
+
+// conversionType finds the type named by the function in a call
+// expression, or returns nil if the function does not name a type
+func (e *evaluator) conversionType(fun ast.Expr) Type {
+	switch fun := fun.(type) {
+	case *ast.ParenExpr:
+		return e.conversionType(fun.X)
+	case *ast.Ident:
+		if t, found := e.b.lookup(fun.Name); found && t != invalidType {
+			return t
+		}
+	case *ast.SelectorExpr:
+		x, ok := fun.X.(*ast.Ident)
+		if !ok {
+			break
+		}
+		if pkg, _ := e.b.importedPackage(x.Name); pkg != nil {
+			if t, found := pkg.lookup(fun.Sel.Name); found && t != invalidType {
+				return t
+			}
+		}
+	}
+	return nil
+}
+
+// countElements finds the length of an array literal such as
+// "[...]string{2: "c", "d"}", in which the elements may have indices
+func (e *evaluator) countElements(lit *ast.CompositeLit) (int, bool) {
+	n, max := 0, 0
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			v, _ := e.eval(kv.Key)
+			i, exact := constant.Int64Val(constant.ToInt(v))
+			if !exact || i < 0 {
+				if v.Kind() != constant.Unknown {
+					e.errorf(kv.Key.Pos(), "", "index %s must be a non-negative integer constant",
+						types.ExprString(kv.Key))
+				}
+				return 0, false
+			}
+			n = int(i)
+		}
+		n++
+		if n > max {
+			max = n
+		}
+	}
+	return max, true
+}
+
+// represent converts a constant value to type t, recording an error if
+// the value cannot be represented by a value of that type
+func (e *evaluator) represent(expr ast.Expr, v constant.Value, t Type) constant.Value {
+	if v.Kind() == constant.Unknown || t == nil || t == invalidType || !e.b.complete(t) {
+		return v
+	}
+
+	var r constant.Value
+	switch k := t.Kind(); k {
+	case reflect.Bool:
+		if v.Kind() == constant.Bool {
+			r = v
+		}
+	case reflect.String:
+		if v.Kind() == constant.String {
+			r = v
+		}
+	case reflect.Float32, reflect.Float64:
+		r = constant.ToFloat(v)
+	case reflect.Complex64, reflect.Complex128:
+		r = constant.ToComplex(v)
+	default:
+		bits, signed := e.b.pkg.sizes.intBits(k)
+		if bits == 0 {
+			e.errorf(expr.Pos(), "", "invalid constant type %s", t)
+			return constant.MakeUnknown()
+		}
+		r = constant.ToInt(v)
+		if r.Kind() != constant.Int {
+			if isNumericValue(v) {
+				e.errorf(expr.Pos(), "", "cannot use %s (untyped constant %s) as %s value (truncated)",
+					types.ExprString(expr), v, t)
+				return constant.MakeUnknown()
+			}
+			r = nil
+			break
+		}
+		min, max := constant.MakeInt64(0), constant.Shift(constant.MakeInt64(1), token.SHL, uint(bits))
+		if signed {
+			max = constant.Shift(constant.MakeInt64(1), token.SHL, uint(bits-1))
+			min = constant.UnaryOp(token.SUB, max, 0)
+		}
+		if constant.Compare(r, token.LSS, min) || constant.Compare(r, token.GEQ, max) {
+			e.errorf(expr.Pos(), "", "constant %s overflows %s", r, t)
+			return constant.MakeUnknown()
+		}
+	}
+	if r == nil || r.Kind() == constant.Unknown {
+		e.errorf(expr.Pos(), "", "cannot convert %s (constant %s) to type %s", types.ExprString(expr), v, t)
+		return constant.MakeUnknown()
+	}
+	return r
+}
+
+// completeLayout populates t and every type that its size depends on, so
+// that the size of t can be computed while the package is still being
+// built. It reports false if the size of t depends on itself.
+func (b *builder) completeLayout(t Type, seen map[Type]bool) bool {
+	if seen[t] || !b.complete(t) {
+		return false
+	}
+	seen[t] = true
+	defer delete(seen, t)
+	switch t.Kind() {
+	case reflect.Array:
+		return b.completeLayout(t.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !b.completeLayout(t.Field(i).Type, seen) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// intBits returns the number of bits in an integer kind and whether it is
+// signed, or zero if the kind is not an integer
+func (s *sizes) intBits(k reflect.Kind) (bits int, signed bool) {
+	switch k {
+	case reflect.Int8:
+		return 8, true
+	case reflect.Int16:
+		return 16, true
+	case reflect.Int32:
+		return 32, true
+	case reflect.Int64:
+		return 64, true
+	case reflect.Int:
+		return int(8 * s.wordSize), true
+	case reflect.Uint8:
+		return 8, false
+	case reflect.Uint16:
+		return 16, false
+	case reflect.Uint32:
+		return 32, false
+	case reflect.Uint64:
+		return 64, false
+	case reflect.Uint, reflect.Uintptr:
+		return int(8 * s.wordSize), false
+	}
+	return 0, false
+}
+
+// isNumericValue reports whether a constant is a number
+func isNumericValue(v constant.Value) bool {
+	switch v.Kind() {
+	case constant.Int, constant.Float, constant.Complex:
+		return true
+	}
+	return false
+}
+
+// defaultType returns the type that an untyped constant takes on when a
+// type is required
+func defaultType(v constant.Value) Type {
+	switch v.Kind() {
+	case constant.Bool:
+		return TypeOf(false)
+	case constant.String:
+		return TypeOf("")
+	case constant.Float:
+		return TypeOf(float64(0))
+	case constant.Complex:
+		return TypeOf(complex128(0))
+	}
+	return TypeOf(0)
+}
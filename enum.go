@@ -0,0 +1,113 @@
+package mold
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+)
+
+// Const describes a package-level constant
+type Const struct {
+	Name    string
+	Type    Type           // type of the constant, or its default type if it is untyped
+	Untyped bool           // whether the constant is untyped, as in "const N = 4"
+	Value   constant.Value // value of the constant
+	Doc     string         // doc comment, if any
+	Pos     token.Position // position of the constant's name
+}
+
+// Enum is a group of constants of the same named type that are declared
+// together using iota, as in
+//
+//	type Color int
+//
+//	const (
+//		Red Color = iota
+//		Green
+//		Blue
+//	)
+type Enum struct {
+	Type   Type // the named type shared by the constants
+	values []*Const
+}
+
+// Values returns the constants of the enum in declaration order.
+func (e *Enum) Values() []*Const {
+	return e.values
+}
+
+// exportConsts evaluates and converts the constants declared in this
+// package, and groups them into enums by type. Constants are evaluated
+// only when they are needed, so a constant that cannot be evaluated, such
+// as one that calls a builtin function the evaluator does not support, is
+// left out and its errors are recorded rather than failing to load the
+// package.
+func (b *builder) exportConsts() (map[string]*Const, map[string]*Enum) {
+	consts := make(map[string]*Const)
+	groups := make(map[*ast.GenDecl][]*Const)
+	var order []*ast.GenDecl
+	for _, c := range b.constList {
+		v, t := b.evalConst(c)
+		if v.Kind() == constant.Unknown || t == invalidType {
+			continue // already reported
+		}
+		exported := &Const{
+			Name:  c.name.Name,
+			Type:  t,
+			Value: v,
+			Doc:   c.doc,
+			Pos:   b.loader.fset.Position(c.name.Pos()),
+		}
+		if t == nil {
+			exported.Type = defaultType(v)
+			exported.Untyped = true
+		}
+		consts[c.name.Name] = exported
+		if groups[c.group] == nil {
+			order = append(order, c.group)
+		}
+		groups[c.group] = append(groups[c.group], exported)
+	}
+
+	enums := make(map[string]*Enum)
+	for _, group := range order {
+		values := groups[group]
+		t := values[0].Type
+		if values[0].Untyped || b.named[t.Name()] != t || !usesIota(group) {
+			continue
+		}
+		shared := true
+		for _, c := range values {
+			if c.Type != t {
+				shared = false
+			}
+		}
+		if !shared {
+			continue
+		}
+		// constants of the same type may be declared in more than one group
+		if enum, found := enums[t.Name()]; found {
+			enum.values = append(enum.values, values...)
+		} else {
+			enums[t.Name()] = &Enum{Type: t, values: values}
+		}
+	}
+	return consts, enums
+}
+
+// usesIota reports whether any of the values in a const declaration
+// refer to iota
+func usesIota(decl *ast.GenDecl) bool {
+	found := false
+	for _, spec := range decl.Specs {
+		for _, v := range spec.(*ast.ValueSpec).Values {
+			ast.Inspect(v, func(n ast.Node) bool {
+				if ident, ok := n.(*ast.Ident); ok && ident.Name == "iota" {
+					found = true
+				}
+				return !found
+			})
+		}
+	}
+	return found
+}
@@ -0,0 +1,101 @@
+package mold
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+)
+
+// ErrorCategory classifies the problems that can be found while
+// constructing types from source.
+type ErrorCategory int
+
+const (
+	// UnknownIdentifier means that a type or package name could not be resolved
+	UnknownIdentifier ErrorCategory = iota + 1
+	// UnsupportedExpression means that a type expression has a form that
+	// cannot be used to construct a type
+	UnsupportedExpression
+	// InvalidArrayLength means that the length of an array type could
+	// not be determined
+	InvalidArrayLength
+	// ImportFailed means that an imported package could not be found or parsed
+	ImportFailed
+	// InvalidDeclaration means that a declaration violates the language
+	// spec, for example by declaring the same method twice
+	InvalidDeclaration
+	// InvalidConstant means that the value of a constant declaration could
+	// not be determined
+	InvalidConstant
+)
+
+func (c ErrorCategory) String() string {
+	switch c {
+	case UnknownIdentifier:
+		return "unknown identifier"
+	case UnsupportedExpression:
+		return "unsupported expression"
+	case InvalidArrayLength:
+		return "invalid array length"
+	case ImportFailed:
+		return "import failed"
+	case InvalidDeclaration:
+		return "invalid declaration"
+	case InvalidConstant:
+		return "invalid constant"
+	default:
+		return fmt.Sprintf("ErrorCategory(%d)", int(c))
+	}
+}
+
+// Error describes a problem found while constructing types from source.
+type Error struct {
+	Pos      token.Position // position of the offending expression
+	Ident    string         // offending identifier, if any
+	Category ErrorCategory
+	Msg      string
+}
+
+func (e *Error) Error() string {
+	if e.Pos.IsValid() {
+		return e.Pos.String() + ": " + e.Msg
+	}
+	return e.Msg
+}
+
+// ErrorList is a list of errors found while constructing types. Loading
+// continues past the first error so that all problems can be reported.
+type ErrorList []*Error
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+}
+
+// Err returns an error equivalent to this error list, or nil if the
+// list is empty.
+func (l ErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
+
+// Sort sorts the list by source position.
+func (l ErrorList) Sort() {
+	sort.SliceStable(l, func(i, j int) bool {
+		a, b := l[i].Pos, l[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
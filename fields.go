@@ -0,0 +1,104 @@
+package mold
+
+import "reflect"
+
+// fieldScan is a struct to be searched for fields, together with the
+// index sequence that leads to it from the outermost struct
+type fieldScan struct {
+	typ   Type
+	index []int
+}
+
+// fieldByNameFunc finds a field in a struct, including fields promoted
+// from embedded structs, following the same algorithm as reflect. The
+// search proceeds breadth first, so a field at a shallower depth shadows
+// fields at greater depths, and if more than one field matches at the
+// shallowest depth then the name is ambiguous and no field is found.
+func fieldByNameFunc(t Type, match func(string) bool) (result StructField, ok bool) {
+	var current []fieldScan
+	next := []fieldScan{{typ: t}}
+
+	// nextCount records the number of times an embedded struct has been
+	// reached at the next depth, since fields reached through a struct that
+	// is embedded twice at the same depth are ambiguous
+	var nextCount map[Type]int
+	visited := make(map[Type]bool)
+
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		count := nextCount
+		nextCount = nil
+
+		for _, scan := range current {
+			st := scan.typ
+			if visited[st] {
+				// a struct embedded at a shallower depth has already been
+				// searched, and any fields found through it would be shadowed
+				continue
+			}
+			visited[st] = true
+
+			for i := 0; i < st.NumField(); i++ {
+				f := st.Field(i)
+				var embedded Type
+				if f.Anonymous {
+					embedded = f.Type
+					if embedded.Kind() == reflect.Ptr {
+						embedded = embedded.Elem()
+					}
+				}
+
+				if match(f.Name) {
+					if count[st] > 1 || ok {
+						// the name appears more than once at this depth
+						return StructField{}, false
+					}
+					result = f
+					result.Index = append(append([]int(nil), scan.index...), i)
+					ok = true
+					continue
+				}
+
+				// search the embedded struct at the next depth, unless a
+				// match has been found at this depth
+				if ok || embedded == nil || embedded.Kind() != reflect.Struct {
+					continue
+				}
+				if nextCount[embedded] > 0 {
+					nextCount[embedded] = 2 // the exact count does not matter
+					continue
+				}
+				if nextCount == nil {
+					nextCount = make(map[Type]int)
+				}
+				nextCount[embedded] = 1
+				if count[st] > 1 {
+					nextCount[embedded] = 2
+				}
+				index := append(append([]int(nil), scan.index...), i)
+				next = append(next, fieldScan{typ: embedded, index: index})
+			}
+		}
+		if ok {
+			break
+		}
+	}
+	return result, ok
+}
+
+// fieldByIndex finds a nested field by calling Field successively for
+// each index, dereferencing pointers to embedded structs along the way
+func fieldByIndex(t Type, index []int) StructField {
+	var f StructField
+	for i, x := range index {
+		if i > 0 {
+			t = f.Type
+			if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct {
+				t = t.Elem()
+			}
+		}
+		f = t.Field(x)
+	}
+	f.Index = append([]int(nil), index...)
+	return f
+}
@@ -0,0 +1,136 @@
+package mold
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+)
+
+// Func describes a top-level function. Its Type is a func type named after
+// the function, whose In, Out and IsVariadic methods describe the
+// signature and whose TypeParam method describes the type parameters of a
+// generic function.
+type Func struct {
+	Name    string
+	Type    Type
+	Params  []string       // names of the input parameters, empty for unnamed parameters
+	Results []string       // names of the results, empty for unnamed results
+	Doc     string         // doc comment, if any
+	Pos     token.Position // position of the function's name
+}
+
+// exportFuncs resolves the signatures of the top-level functions declared
+// in this package. Signatures are resolved only when the package is
+// exported, so that a function whose signature is invalid, such as one
+// that refers to a package that cannot be imported, is left out and its
+// errors are recorded rather than failing to load the types in the package.
+func (b *builder) exportFuncs() map[string]*Func {
+	funcs := make(map[string]*Func)
+	for _, fd := range b.funcDecls {
+		f := b.populateFunction(fd)
+		b.loader.build() // populate any instantiations in the signature
+
+		// types that could not be resolved may have been reported already,
+		// such as when an import failed, but validation reports every
+		// problem that it finds
+		n := len(b.loader.errors)
+		b.validateExpr(fd.decl.Type, f.Type)
+		if len(b.loader.errors) == n && !invalidSignature(f.Type) {
+			funcs[f.Name] = f
+		}
+	}
+	return funcs
+}
+
+// hasInvalid reports whether the invalid type appears within an unnamed
+// type, which happens when it refers to types that could not be resolved
+func hasInvalid(t Type) bool {
+	switch {
+	case t == invalidType:
+		return true
+	case t.Name() != "":
+		return false // named types are checked at their declarations
+	}
+	switch t.Kind() {
+	case reflect.Array, reflect.Chan, reflect.Ptr, reflect.Slice:
+		return hasInvalid(t.Elem())
+	case reflect.Map:
+		return hasInvalid(t.Key()) || hasInvalid(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if hasInvalid(t.Field(i).Type) {
+				return true
+			}
+		}
+	case reflect.Func:
+		return invalidSignature(t)
+	}
+	return false
+}
+
+// invalidSignature reports whether the invalid type appears in the
+// parameters or results of a function type
+func invalidSignature(t Type) bool {
+	for i := 0; i < t.NumIn(); i++ {
+		if hasInvalid(t.In(i)) {
+			return true
+		}
+	}
+	for i := 0; i < t.NumOut(); i++ {
+		if hasInvalid(t.Out(i)) {
+			return true
+		}
+	}
+	return false
+}
+
+// populateFunction resolves the signature of a top-level function. Type
+// parameters are in scope within the signature, as for a generic type.
+func (b *builder) populateFunction(fd *funcDecl) *Func {
+	name := fd.decl.Name.Name
+	t := makeSkeleton(fd.decl.Type, name, b.pkg).(*staticFunc)
+
+	file, scope := b.file, b.scope
+	b.file, b.scope = fd.file, nil
+	if fields := fd.decl.Type.TypeParams; fields != nil {
+		t.typeParams = makeTypeParams(fields, b.pkg)
+		var params []Type
+		for _, p := range t.typeParams {
+			params = append(params, p)
+		}
+		b.scope = typeParamScope(fields, params)
+		b.populateTypeParams(fields, t.typeParams)
+	}
+	b.populate(t)
+	b.file, b.scope = file, scope
+
+	f := &Func{
+		Name:    name,
+		Type:    t,
+		Params:  paramNames(fd.decl.Type.Params),
+		Results: paramNames(fd.decl.Type.Results),
+		Pos:     b.loader.fset.Position(fd.decl.Name.Pos()),
+	}
+	if fd.decl.Doc != nil {
+		f.Doc = fd.decl.Doc.Text()
+	}
+	return f
+}
+
+// paramNames lists the names in a parameter or result list, with one
+// entry per parameter
+func paramNames(fields *ast.FieldList) []string {
+	if fields == nil {
+		return nil
+	}
+	var names []string
+	for _, f := range fields.List {
+		if len(f.Names) == 0 {
+			names = append(names, "")
+		}
+		for _, ident := range f.Names {
+			names = append(names, ident.Name)
+		}
+	}
+	return names
+}
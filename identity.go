@@ -0,0 +1,96 @@
+package mold
+
+import "reflect"
+
+// identical reports whether x and y are identical types, as defined at
+// https://golang.org/ref/spec#Type_identity. Named types are identical if
+// they have the same name and package path, so a type loaded from source
+// is identical to the live type for the same declaration.
+func identical(x, y Type) bool {
+	return identicalTypes(x, y, false)
+}
+
+// identicalIgnoreTags reports whether x and y are identical types when
+// struct tags are ignored, as required for conversions
+func identicalIgnoreTags(x, y Type) bool {
+	return identicalTypes(x, y, true)
+}
+
+func identicalTypes(x, y Type, ignoreTags bool) bool {
+	if x == y {
+		return true
+	}
+	if x == invalidType || y == invalidType {
+		return false
+	}
+	if _, ok := x.(*staticTypeParam); ok {
+		return false // type parameters are only identical to themselves
+	}
+	if _, ok := y.(*staticTypeParam); ok {
+		return false
+	}
+	if x.Name() != "" || y.Name() != "" {
+		return x.Name() == y.Name() && x.PkgPath() == y.PkgPath()
+	}
+	if x.Kind() != y.Kind() {
+		return false
+	}
+
+	switch x.Kind() {
+	case reflect.Array:
+		return x.Len() == y.Len() && identicalTypes(x.Elem(), y.Elem(), ignoreTags)
+	case reflect.Slice, reflect.Ptr:
+		return identicalTypes(x.Elem(), y.Elem(), ignoreTags)
+	case reflect.Map:
+		return identicalTypes(x.Key(), y.Key(), ignoreTags) && identicalTypes(x.Elem(), y.Elem(), ignoreTags)
+	case reflect.Chan:
+		return x.ChanDir() == y.ChanDir() && identicalTypes(x.Elem(), y.Elem(), ignoreTags)
+	case reflect.Func:
+		if x.NumIn() != y.NumIn() || x.NumOut() != y.NumOut() || x.IsVariadic() != y.IsVariadic() {
+			return false
+		}
+		for i := 0; i < x.NumIn(); i++ {
+			if !identicalTypes(x.In(i), y.In(i), ignoreTags) {
+				return false
+			}
+		}
+		for i := 0; i < x.NumOut(); i++ {
+			if !identicalTypes(x.Out(i), y.Out(i), ignoreTags) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		if x.NumField() != y.NumField() {
+			return false
+		}
+		for i := 0; i < x.NumField(); i++ {
+			f, g := x.Field(i), y.Field(i)
+			if f.Name != g.Name || f.PkgPath != g.PkgPath || (!ignoreTags && f.Tag != g.Tag) || f.Anonymous != g.Anonymous {
+				return false
+			}
+			if !identicalTypes(f.Type, g.Type, ignoreTags) {
+				return false
+			}
+		}
+		return true
+	case reflect.Interface:
+		xm, ym := interfaceMethods(x), interfaceMethods(y)
+		if len(xm) != len(ym) {
+			return false
+		}
+		for i := range xm {
+			if xm[i].name != ym[i].name || xm[i].pkgPath != ym[i].pkgPath {
+				return false
+			}
+			if !identicalTypes(xm[i].signature(), ym[i].signature(), ignoreTags) {
+				return false
+			}
+		}
+		return true
+	default:
+		// unnamed basic types only arise from live types, which are
+		// compared directly above
+		return false
+	}
+}
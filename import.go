@@ -0,0 +1,247 @@
+package mold
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// loader constructs types for one package and for every package it imports.
+// Imported packages are shared between all files that refer to them, so
+// that e.g. time.Time is the same Type wherever it appears.
+type loader struct {
+	fset      *token.FileSet
+	ctxt      build.Context
+	packages  map[string]*builder       // imported packages, by import path
+	builders  []*builder                // all packages, in the order they were created
+	decls     map[Type]*typeDecl        // declarations of named types in all packages
+	instances map[*typeDecl][]*typeDecl // instantiations of each generic declaration
+	current   *typeDecl                 // declaration being populated
+	pkgPath   string                    // import path of the package being loaded, if overridden
+	sizes     *sizes                    // layout of types on the target architecture
+	structs   []*staticStruct           // structs that have not been laid out yet
+	building  bool                      // whether build is in progress
+	errors    ErrorList
+}
+
+func newLoader(opts ...Option) (*loader, error) {
+	l := &loader{
+		fset:      token.NewFileSet(),
+		ctxt:      build.Default,
+		packages:  make(map[string]*builder),
+		decls:     make(map[Type]*typeDecl),
+		instances: make(map[*typeDecl][]*typeDecl),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.sizes = sizesFor(l.ctxt.GOARCH); l.sizes == nil {
+		return nil, fmt.Errorf("unsupported architecture: %s", l.ctxt.GOARCH)
+	}
+	return l, nil
+}
+
+func (l *loader) newBuilder(name, path, dir string) *builder {
+	b := newBuilder(&pkgInfo{name: name, path: path, sizes: l.sizes})
+	b.dir = dir
+	b.loader = l
+	l.builders = append(l.builders, b)
+	return b
+}
+
+// importPackage locates the sources for the package with the given import
+// path, as seen from srcDir, and parses them. Types within the package are
+// only constructed once they are looked up. If the package cannot be loaded
+// then the error is returned the first time and nil is returned thereafter.
+func (l *loader) importPackage(importPath, srcDir string) (*builder, error) {
+	if b, found := l.packages[importPath]; found {
+		return b, nil
+	}
+
+	if importPath == "unsafe" {
+		b := l.newBuilder("unsafe", "unsafe", "")
+		b.symbols["Pointer"] = TypeOf(unsafe.Pointer(nil))
+		l.packages[importPath] = b
+		return b, nil
+	}
+
+	// record the failure so that it is only reported once
+	l.packages[importPath] = nil
+
+	bp, err := l.ctxt.Import(importPath, srcDir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*ast.File
+	for _, name := range bp.GoFiles {
+		file, err := parser.ParseFile(l.fset, filepath.Join(bp.Dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	b := l.newBuilder(bp.Name, bp.ImportPath, bp.Dir)
+	b.imported = true
+	for _, file := range files {
+		b.addFile(file)
+	}
+	l.packages[importPath] = b
+	return b, nil
+}
+
+// build populates every type that has been looked up so far, including
+// types from imported packages that were reached along the way, and then
+// computes the layout of the structs among them. A call made while types
+// are being populated returns immediately, since the structs cannot be
+// laid out until the outermost call has finished populating every type.
+func (l *loader) build() {
+	if l.building || l.current != nil {
+		return
+	}
+	l.building = true
+	defer func() { l.building = false }()
+
+	for progress := true; progress; {
+		progress = false
+		for i := 0; i < len(l.builders); i++ {
+			if l.builders[i].populatePending() {
+				progress = true
+			}
+		}
+	}
+	for _, t := range l.structs {
+		t.computeLayout(l.sizes)
+	}
+	l.structs = nil
+}
+
+// sourceFile holds the file-level scope needed to resolve identifiers
+// within a single source file
+type sourceFile struct {
+	imports []*ast.ImportSpec
+	cgo     bool // whether the use of cgo has been reported
+}
+
+// importedPackage finds the package referred to by name within the file
+// currently being populated. If the package was found but could not be
+// loaded then an error is recorded and a nil builder is returned.
+func (b *builder) importedPackage(name string) (*builder, bool) {
+	if b.file == nil {
+		return nil, false
+	}
+
+	// The name under which an import is visible is the package clause of the
+	// imported package, which is usually but not always the last element of
+	// the import path, so try the likely candidates before loading the rest.
+	var unnamed []*ast.ImportSpec
+	for _, spec := range b.file.imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if importPath == "C" {
+			if name != "C" {
+				continue
+			}
+			// the types declared by cgo only exist once cgo has processed
+			// the file, so report them once rather than each unknown type
+			if !b.file.cgo {
+				b.errorf(spec.Path.Pos(), UnsupportedExpression, "C", "cgo is not supported")
+				b.file.cgo = true
+			}
+			return nil, true
+		}
+		if spec.Name != nil {
+			if spec.Name.Name == name {
+				return b.importSpec(spec), true
+			}
+			continue
+		}
+		if guessPackageName(importPath) == name {
+			unnamed = append([]*ast.ImportSpec{spec}, unnamed...)
+		} else {
+			unnamed = append(unnamed, spec)
+		}
+	}
+
+	for _, spec := range unnamed {
+		pkg := b.importSpec(spec)
+		if pkg != nil && pkg.pkg.name == name {
+			return pkg, true
+		}
+		// if the import failed then its package name is unknown, so assume
+		// that it is the guessed name rather than reporting a second error
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		if pkg == nil && guessPackageName(importPath) == name {
+			return nil, true
+		}
+	}
+	return nil, false
+}
+
+// dotImports finds the packages whose exported identifiers are imported
+// into the file currently being populated by an import such as
+// `import . "time"`. It also reports whether any of them failed to load,
+// in which case an identifier that cannot be found may have been declared
+// in the failed package.
+func (b *builder) dotImports() (pkgs []*builder, failed bool) {
+	if b.file == nil {
+		return nil, false
+	}
+	for _, spec := range b.file.imports {
+		if spec.Name == nil || spec.Name.Name != "." {
+			continue
+		}
+		if pkg := b.importSpec(spec); pkg != nil {
+			pkgs = append(pkgs, pkg)
+		} else {
+			failed = true
+		}
+	}
+	return pkgs, failed
+}
+
+// importSpec loads the package imported by spec, recording an error if
+// it cannot be loaded
+func (b *builder) importSpec(spec *ast.ImportSpec) *builder {
+	importPath, _ := strconv.Unquote(spec.Path.Value)
+	pkg, err := b.loader.importPackage(importPath, b.dir)
+	if err != nil {
+		b.errorf(spec.Path.Pos(), ImportFailed, importPath, "error importing %s: %v", importPath, err)
+	}
+	return pkg
+}
+
+// guessPackageName guesses the package name from an import path,
+// ignoring major version suffixes such as "/v2" or ".v3"
+func guessPackageName(importPath string) string {
+	base := path.Base(importPath)
+	if strings.HasPrefix(base, "v") && isNumeric(base[1:]) && importPath != base {
+		base = path.Base(path.Dir(importPath))
+	}
+	if pos := strings.LastIndex(base, ".v"); pos > 0 && isNumeric(base[pos+2:]) {
+		base = base[:pos]
+	}
+	return strings.TrimPrefix(base, "go-")
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+package mold
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// maxInstanceDepth limits how deeply instantiations may be nested within
+// one another, which would otherwise be unbounded for declarations such
+// as "type T[P any] struct { next *T[[]P] }"
+const maxInstanceDepth = 100
+
+// instantiate resolves a generic type applied to type arguments, such as
+// "Page[User]" or "Result[int, error]"
+func (b *builder) instantiate(expr, x ast.Expr, indices []ast.Expr) Type {
+	generic := b.resolveName(x)
+	if generic == invalidType {
+		return invalidType
+	}
+	decl, found := b.loader.decls[generic]
+	if !found || decl.args != nil || generic.NumTypeParam() == 0 {
+		b.errorf(x.Pos(), UnsupportedExpression, generic.Name(),
+			"%s is not a generic type", generic)
+		return invalidType
+	}
+
+	var args []Type
+	for _, index := range indices {
+		arg := b.resolve(index)
+		if arg == invalidType {
+			return invalidType
+		}
+		args = append(args, arg)
+	}
+	if len(args) != generic.NumTypeParam() {
+		b.errorf(expr.Pos(), UnsupportedExpression, generic.Name(),
+			"wrong number of type arguments for %s: have %d, want %d",
+			generic, len(args), generic.NumTypeParam())
+		return invalidType
+	}
+
+	t, ok := b.loader.instantiate(decl, args)
+	if !ok {
+		if !decl.cyclic {
+			b.errorf(expr.Pos(), InvalidDeclaration, generic.Name(),
+				"instantiation cycle in %s", generic)
+			decl.cyclic = true
+		}
+		return invalidType
+	}
+	return t
+}
+
+// instantiate finds or creates the instantiation of a generic declaration
+// with the given type arguments, so that identical instantiations are
+// represented by the same Type. The instantiation is populated later by
+// resolving the generic declaration with its type parameters bound to the
+// type arguments. It reports false if instantiations are nested too deeply.
+func (l *loader) instantiate(generic *typeDecl, args []Type) (Type, bool) {
+	// Within its own declaration, a generic type applied to its own type
+	// parameters denotes the generic type itself, as in
+	// "type List[T any] struct { next *List[T] }"
+	own := true
+	for i, param := range generic.typeArgs() {
+		if args[i] != param {
+			own = false
+		}
+	}
+	if own {
+		return generic.typ, true
+	}
+
+	for _, inst := range l.instances[generic] {
+		if identicalArgs(inst.args, args) {
+			return inst.typ, true
+		}
+	}
+
+	depth := 0
+	if l.current != nil {
+		depth = l.current.depth + 1
+	}
+	if depth > maxInstanceDepth {
+		return nil, false
+	}
+
+	var argStrings []string
+	for _, arg := range args {
+		argStrings = append(argStrings, arg.String())
+	}
+	name := generic.spec.Name.Name + "[" + strings.Join(argStrings, ",") + "]"
+
+	b := generic.builder
+	inst := &typeDecl{
+		spec:    generic.spec,
+		file:    generic.file,
+		builder: b,
+		typ:     makeSkeleton(generic.spec.Type, name, b.pkg),
+		args:    args,
+		depth:   depth,
+	}
+	l.instances[generic] = append(l.instances[generic], inst)
+	l.decls[inst.typ] = inst
+	b.pending = append(b.pending, inst)
+	return inst.typ, true
+}
+
+// identicalArgs reports whether two lists of type arguments are identical
+func identicalArgs(xs, ys []Type) bool {
+	if len(xs) != len(ys) {
+		return false
+	}
+	for i := range xs {
+		if !identical(xs[i], ys[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+package mold
+
+import (
+	"reflect"
+	"sort"
+)
+
+// StructLayout describes how the fields of a struct are laid out in
+// memory, and how they could be reordered to minimize padding
+type StructLayout struct {
+	Size    uintptr       // size of the struct with fields in declaration order
+	Align   int           // alignment of the struct
+	Fields  []FieldLayout // fields in declaration order
+	Padding uintptr       // total bytes of padding between and after fields
+
+	// OptimalSize is the smallest size that the struct can have when its
+	// fields are reordered, and OptimalOrder lists the indices of the fields
+	// in an order that achieves it. If the struct already has the smallest
+	// size then OptimalOrder is the declaration order.
+	OptimalSize  uintptr
+	OptimalOrder []int
+}
+
+// FieldLayout describes the position of a single field within a struct
+type FieldLayout struct {
+	Name    string
+	Type    Type
+	Offset  uintptr // offset within struct, in bytes
+	Size    uintptr // size of the field, in bytes
+	Align   int     // alignment of the field, in bytes
+	Padding uintptr // bytes of padding between this field and the next, or the end of the struct
+}
+
+// Layout computes the layout of a struct type and a field order that
+// minimizes its size, similar to the fieldalignment analyzer. For types
+// loaded from source, sizes are computed for the architecture that the
+// types were loaded for. It panics if the type's Kind is not Struct.
+func Layout(t Type) *StructLayout {
+	if t.Kind() != reflect.Struct {
+		panic("Layout of non-struct type " + t.String())
+	}
+	s := sizesOf(t)
+
+	var types []Type
+	for i := 0; i < t.NumField(); i++ {
+		types = append(types, t.Field(i).Type)
+	}
+	size, align, offsets := s.layoutFields(types)
+
+	layout := StructLayout{
+		Size:  uintptr(size),
+		Align: int(align),
+	}
+	for i, f := range types {
+		end := size
+		if i+1 < len(offsets) {
+			end = offsets[i+1]
+		}
+		n := s.sizeof(f)
+		layout.Fields = append(layout.Fields, FieldLayout{
+			Name:    t.Field(i).Name,
+			Type:    f,
+			Offset:  uintptr(offsets[i]),
+			Size:    uintptr(n),
+			Align:   int(s.alignof(f)),
+			Padding: uintptr(end - offsets[i] - n),
+		})
+		layout.Padding += uintptr(end - offsets[i] - n)
+	}
+
+	// Placing zero-sized fields first avoids the padding added after a
+	// trailing zero-sized field, and placing the remaining fields in order
+	// of decreasing alignment leaves no gaps between them, since every size
+	// is a multiple of the corresponding alignment.
+	order := make([]int, len(types))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		fi, fj := layout.Fields[order[i]], layout.Fields[order[j]]
+		if (fi.Size == 0) != (fj.Size == 0) {
+			return fi.Size == 0
+		}
+		return fi.Align > fj.Align
+	})
+	var reordered []Type
+	for _, i := range order {
+		reordered = append(reordered, types[i])
+	}
+	optimal, _, _ := s.layoutFields(reordered)
+	if optimal == size {
+		// the declaration order is already optimal
+		sort.Ints(order)
+	}
+
+	layout.OptimalSize = uintptr(optimal)
+	layout.OptimalOrder = order
+	return &layout
+}
+
+// sizesOf returns the layout rules for the architecture that a type was
+// loaded for
+func sizesOf(t Type) *sizes {
+	if named, ok := t.(interface{ base() *staticType }); ok {
+		return named.base().sizes()
+	}
+	return hostSizes
+}
@@ -10,23 +10,73 @@ func (t liveType) AssignableTo(u Type) bool {
 	if u, ok := u.(liveType); ok {
 		return t.Type.AssignableTo(u.Type)
 	}
-	return false
+	return assignableTo(t, u)
 }
 
 func (t liveType) ConvertibleTo(u Type) bool {
 	if u, ok := u.(liveType); ok {
 		return t.Type.ConvertibleTo(u.Type)
 	}
-	return false
+	return convertibleTo(t, u)
 }
 
 func (t liveType) Implements(u Type) bool {
 	if u, ok := u.(liveType); ok {
 		return t.Type.Implements(u.Type)
 	}
+	return implementsType(t, u)
+}
+
+// NumTypeParam returns zero since live types are never generic: reflect
+// only ever sees instantiated types.
+func (t liveType) NumTypeParam() int {
+	return 0
+}
+
+func (t liveType) TypeParam(i int) TypeParam {
+	panic("TypeParam of non-generic type " + t.String())
+}
+
+// IsMethodSet reports true for all live interfaces, since constraint
+// interfaces only exist at compile time.
+func (t liveType) IsMethodSet() bool {
+	if t.Kind() != reflect.Interface {
+		panic("IsMethodSet of non-interface type " + t.String())
+	}
+	return true
+}
+
+func (t liveType) NumTerm() int {
+	if t.Kind() != reflect.Interface {
+		panic("NumTerm of non-interface type " + t.String())
+	}
+	return 0
+}
+
+// IsEmptyTypeSet reports false for all live interfaces, since every live
+// interface is satisfied by at least the types that implement it.
+func (t liveType) IsEmptyTypeSet() bool {
+	if t.Kind() != reflect.Interface {
+		panic("IsEmptyTypeSet of non-interface type " + t.String())
+	}
 	return false
 }
 
+func (t liveType) Term(i int) Term {
+	panic("Term of interface type without type terms")
+}
+
+func (t liveType) Satisfies(u Type) bool {
+	if t.Kind() != reflect.Interface {
+		panic("Satisfies of non-interface type " + t.String())
+	}
+	return satisfies(u, t)
+}
+
+func (t liveType) Underlying() Type {
+	return liveUnderlying(t.Type)
+}
+
 func (t liveType) Key() Type {
 	return liveType{t.Type.Key()}
 }
@@ -43,6 +93,36 @@ func (t liveType) Out(i int) Type {
 	return liveType{t.Type.Out(i)}
 }
 
+// liveMethod converts a method in the method set of t. Since reflect does
+// not record receivers, a method of a pointer type *T is reported as having
+// a pointer receiver when it is not also in the method set of T.
+func liveMethod(t reflect.Type, m reflect.Method) Method {
+	var ptrRecv bool
+	if t.Kind() == reflect.Ptr {
+		_, found := t.Elem().MethodByName(m.Name)
+		ptrRecv = !found
+	}
+	return Method{
+		Name:            m.Name,
+		PkgPath:         m.PkgPath,
+		Type:            liveType{m.Type},
+		Index:           m.Index,
+		PointerReceiver: ptrRecv,
+	}
+}
+
+func (t liveType) Method(i int) Method {
+	return liveMethod(t.Type, t.Type.Method(i))
+}
+
+func (t liveType) MethodByName(name string) (Method, bool) {
+	m, b := t.Type.MethodByName(name)
+	if !b {
+		return Method{}, false
+	}
+	return liveMethod(t.Type, m), true
+}
+
 func structField(f reflect.StructField) StructField {
 	return StructField{
 		Name:      f.Name,
@@ -50,6 +130,7 @@ func structField(f reflect.StructField) StructField {
 		Type:      liveType{f.Type},
 		Tag:       StructTag(f.Tag),
 		Offset:    f.Offset,
+		Index:     f.Index,
 		Anonymous: f.Anonymous,
 	}
 }
@@ -1,46 +1,181 @@
 package mold
 
 import (
+	"go/build"
 	"go/parser"
-	"go/token"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
-// LoadTypes loads all top-level functions and symbols from a source file
-func LoadTypes(r io.Reader) (map[string]Type, error) {
-	fset := token.NewFileSet()
-	file, err := parser.ParseFile(fset, "src.go", r, 0)
+// Package is the set of types, constants and functions declared in a package
+type Package struct {
+	Name   string            // package name, as in the package clause
+	Path   string            // import path
+	Types  map[string]Type   // types declared in any file in the package
+	Consts map[string]*Const // constants declared in any file in the package
+	Funcs  map[string]*Func  // top-level functions with valid signatures declared in any file in the package
+	Errors ErrorList         // problems with the constants and functions that were left out
+
+	aliases map[string]bool
+	enums   map[string]*Enum
+}
+
+// IsAlias reports whether name was declared as an alias, as in
+// "type A = B", in which case Types[name] is the type that it denotes.
+func (p *Package) IsAlias(name string) bool {
+	return p.aliases[name]
+}
+
+// Enum returns the constants of the named type that were declared using
+// iota, or nil if there are none.
+func (p *Package) Enum(name string) *Enum {
+	return p.enums[name]
+}
+
+// LoadTypes loads the types declared in a source file.
+// Since the source has no location, the package path of the types is the
+// package name unless it is set with WithPkgPath.
+func LoadTypes(r io.Reader, opts ...Option) (map[string]Type, error) {
+	wd, err := os.Getwd()
 	if err != nil {
 		return nil, err
 	}
 
-	b := newBuilder(file.Name.Name)
-	for _, decl := range file.Decls {
-		b.add(decl)
+	l, err := newLoader(opts...)
+	if err != nil {
+		return nil, err
+	}
+	file, err := parser.ParseFile(l.fset, "src.go", r, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	path := file.Name.Name
+	if l.pkgPath != "" {
+		path = l.pkgPath
+	}
+	b := l.newBuilder(file.Name.Name, path, wd)
+	b.addFile(file)
+	if err := b.build(); err != nil {
+		return nil, err
 	}
-	b.build()
 	return b.named, nil
 }
 
-// LoadFile loads all top-level functions and symbols from a source file
-func LoadFile(path string) (map[string]Type, error) {
+// LoadFile loads the types declared in a source file
+func LoadFile(path string, opts ...Option) (map[string]Type, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
 
-	fset := token.NewFileSet()
-	file, err := parser.ParseFile(fset, path, f, 0)
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := newLoader(opts...)
+	if err != nil {
+		return nil, err
+	}
+	file, err := parser.ParseFile(l.fset, path, f, parser.ParseComments)
 	if err != nil {
 		return nil, err
 	}
 
-	b := newBuilder(file.Name.Name)
-	for _, decl := range file.Decls {
-		b.add(decl)
+	b := l.newBuilder(file.Name.Name, l.importPath(dir, file.Name.Name), dir)
+	b.addFile(file)
+	if err := b.build(); err != nil {
+		return nil, err
 	}
-	b.build()
 	return b.named, nil
 }
+
+// LoadDir loads the types declared in the non-test source files in a
+// directory, which must all belong to the same package. Use LoadPackage
+// to also load the constants and functions of the package.
+func LoadDir(dir string, opts ...Option) (map[string]Type, error) {
+	pkg, err := LoadPackage(dir, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return pkg.Types, nil
+}
+
+// LoadPackage loads the package in a directory. All non-test source files
+// that match the current build context are loaded, so types may refer to
+// types declared in other files in the same package.
+func LoadPackage(dir string, opts ...Option) (*Package, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := newLoader(opts...)
+	if err != nil {
+		return nil, err
+	}
+	var b *builder
+	var first string // name of the first file, for error messages
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		match, err := l.ctxt.MatchFile(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		if !match {
+			continue
+		}
+
+		file, err := parser.ParseFile(l.fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+
+		if b == nil {
+			b = l.newBuilder(file.Name.Name, l.importPath(dir, file.Name.Name), dir)
+			first = name
+		} else if file.Name.Name != b.pkg.name {
+			return nil, &build.MultiplePackageError{
+				Dir:      dir,
+				Packages: []string{b.pkg.name, file.Name.Name},
+				Files:    []string{first, name},
+			}
+		}
+		b.addFile(file)
+	}
+
+	if b == nil {
+		return nil, &build.NoGoError{Dir: dir}
+	}
+
+	if err := b.build(); err != nil {
+		return nil, err
+	}
+	// problems with constants and functions do not prevent the types from
+	// being loaded, so they are returned with the package
+	consts, enums := b.exportConsts()
+	funcs := b.exportFuncs()
+	b.loader.errors.Sort()
+	return &Package{
+		Name:    b.pkg.name,
+		Path:    b.pkg.path,
+		Types:   b.named,
+		Consts:  consts,
+		Funcs:   funcs,
+		Errors:  b.loader.errors,
+		aliases: b.aliases,
+		enums:   enums,
+	}, nil
+}
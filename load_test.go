@@ -1,8 +1,16 @@
 package mold
 
 import (
+	"fmt"
+	"go/build"
+	"go/constant"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
+	"unsafe"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -41,3 +49,1909 @@ func TestLoadFile_Person(t *testing.T) {
 	makerField := person.Field(3)
 	assert.Equal(t, "maker", makerField.Name)
 }
+
+func TestLoadFile_Imports(t *testing.T) {
+	types, err := LoadFile("testdata/event.go")
+	require.NoError(t, err)
+
+	event := types["Event"]
+	require.NotNil(t, event)
+	require.Equal(t, 3, event.NumField())
+
+	when := event.Field(0).Type
+	assert.Equal(t, reflect.Struct, when.Kind())
+	assert.Equal(t, "Time", when.Name())
+	assert.Equal(t, "time", when.PkgPath())
+
+	loc := when.Field(2)
+	assert.Equal(t, "loc", loc.Name)
+	assert.Equal(t, reflect.Ptr, loc.Type.Kind())
+	assert.Equal(t, "Location", loc.Type.Elem().Name())
+	assert.Equal(t, reflect.Struct, loc.Type.Elem().Kind())
+
+	header := event.Field(1).Type
+	assert.Equal(t, reflect.Map, header.Kind())
+	assert.Equal(t, "Header", header.Name())
+	assert.Equal(t, "net/http", header.PkgPath())
+	assert.Equal(t, reflect.Slice, header.Elem().Kind())
+
+	timeout := event.Field(2).Type
+	assert.Equal(t, reflect.Int64, timeout.Kind())
+	assert.Equal(t, "Duration", timeout.Name())
+	assert.Equal(t, "time", timeout.PkgPath())
+}
+
+func TestLoadTypes_ImportFailed(t *testing.T) {
+	src := `package test
+
+import "example.com/missing"
+
+type A struct {
+	X missing.Thing
+	Y *missing.Other
+}
+`
+	_, err := LoadTypes(strings.NewReader(src))
+	require.Error(t, err)
+
+	errs, ok := err.(ErrorList)
+	require.True(t, ok)
+	require.Len(t, errs, 1)
+	assert.Equal(t, ImportFailed, errs[0].Category)
+	assert.Equal(t, "example.com/missing", errs[0].Ident)
+}
+
+func TestLoadTypes_DotImport(t *testing.T) {
+	src := `package test
+
+import . "time"
+
+type T struct {
+	D Duration
+	M *Month
+	A [Nanosecond * 2]int
+}
+`
+	types, err := LoadTypes(strings.NewReader(src))
+	require.NoError(t, err)
+
+	typ := types["T"]
+	assert.Equal(t, "time.Duration", typ.Field(0).Type.String())
+	assert.Equal(t, "*time.Month", typ.Field(1).Type.String())
+	assert.Equal(t, 2, typ.Field(2).Type.Len())
+
+	src = `package test
+
+import . "example.com/missing"
+
+type T struct {
+	X Thing
+}
+`
+	_, err = LoadTypes(strings.NewReader(src))
+	require.Error(t, err)
+	errs, ok := err.(ErrorList)
+	require.True(t, ok)
+	require.Len(t, errs, 1)
+	assert.Equal(t, ImportFailed, errs[0].Category)
+}
+
+func TestLoadTypes_Cgo(t *testing.T) {
+	src := `package test
+
+// #include <stdint.h>
+import "C"
+
+type T struct {
+	X C.int
+	Y *C.uint8_t
+}
+`
+	_, err := LoadTypes(strings.NewReader(src))
+	require.Error(t, err)
+
+	errs, ok := err.(ErrorList)
+	require.True(t, ok)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "cgo is not supported", errs[0].Msg)
+	assert.Equal(t, UnsupportedExpression, errs[0].Category)
+	assert.Equal(t, 4, errs[0].Pos.Line)
+}
+
+func TestLoadDir(t *testing.T) {
+	types, err := LoadDir("testdata")
+	require.NoError(t, err)
+
+	family := types["Family"]
+	require.NotNil(t, family)
+	require.Equal(t, 2, family.NumField())
+
+	// types declared in sibling files are shared rather than duplicated
+	assert.True(t, family.Field(0).Type.Elem() == types["Person"])
+	assert.True(t, family.Field(1).Type.Elem() == types["Event"])
+}
+
+func TestLoadPackage(t *testing.T) {
+	pkg, err := LoadPackage("testdata")
+	require.NoError(t, err)
+	assert.Equal(t, "test", pkg.Name)
+	assert.Contains(t, pkg.Types, "Person")
+	assert.Contains(t, pkg.Types, "Event")
+}
+
+func TestLoadPackage_PkgPath(t *testing.T) {
+	pkg, err := LoadPackage("testdata")
+	require.NoError(t, err)
+	assert.Equal(t, "github.com/alexflint/go-mold/testdata", pkg.Path)
+
+	person := pkg.Types["Person"]
+	assert.Equal(t, "Person", person.Name())
+	assert.Equal(t, "github.com/alexflint/go-mold/testdata", person.PkgPath())
+	assert.Equal(t, "test.Person", person.String())
+	assert.Equal(t, "", person.Field(2).Type.PkgPath())
+
+	pkg, err = LoadPackage("testdata", WithPkgPath("example.com/people"))
+	require.NoError(t, err)
+	assert.Equal(t, "example.com/people", pkg.Types["Person"].PkgPath())
+
+	// source without a location is identified by its package name
+	types, err := LoadTypes(strings.NewReader("package foo\ntype T int"))
+	require.NoError(t, err)
+	assert.Equal(t, "foo", types["T"].PkgPath())
+}
+
+func TestModulePath(t *testing.T) {
+	assert.Equal(t, "example.com/m", modulePath([]byte("// comment\nmodule example.com/m // trailing\n\ngo 1.21\n")))
+	assert.Equal(t, "example.com/q", modulePath([]byte(`module "example.com/q"`)))
+	assert.Equal(t, "", modulePath([]byte("go 1.21\n")))
+	assert.Equal(t, "", modulePath([]byte("modulefoo/bar\n")))
+	assert.Equal(t, "", modulePath([]byte("module\n")))
+	assert.Equal(t, "example.com/t", modulePath([]byte("module\texample.com/t\n")))
+}
+
+func TestLoadPackage_Mixed(t *testing.T) {
+	_, err := LoadPackage("testdata/mixed")
+	require.Error(t, err)
+	assert.IsType(t, &build.MultiplePackageError{}, err)
+}
+
+func TestLoadTypes_Errors(t *testing.T) {
+	src := `package test
+
+type A struct {
+	X Missing
+	Y [n]int
+	Z nosuch.Type
+}
+
+type B [3.5]string
+`
+	types, err := LoadTypes(strings.NewReader(src))
+	require.Error(t, err)
+	assert.Nil(t, types)
+
+	errs, ok := err.(ErrorList)
+	require.True(t, ok)
+	require.Len(t, errs, 4)
+
+	assert.Equal(t, UnknownIdentifier, errs[0].Category)
+	assert.Equal(t, "Missing", errs[0].Ident)
+	assert.Equal(t, 4, errs[0].Pos.Line)
+
+	assert.Equal(t, InvalidArrayLength, errs[1].Category)
+	assert.Equal(t, 5, errs[1].Pos.Line)
+
+	assert.Equal(t, UnknownIdentifier, errs[2].Category)
+	assert.Equal(t, "nosuch", errs[2].Ident)
+	assert.Equal(t, 6, errs[2].Pos.Line)
+
+	assert.Equal(t, InvalidArrayLength, errs[3].Category)
+	assert.Equal(t, 9, errs[3].Pos.Line)
+}
+
+func TestLoadFile_Func(t *testing.T) {
+	types, err := LoadFile("testdata/handlers/handler.go")
+	require.NoError(t, err)
+
+	handler := types["Handler"]
+	require.NotNil(t, handler)
+	assert.Equal(t, reflect.Func, handler.Kind())
+	require.Equal(t, 2, handler.NumIn())
+	assert.Equal(t, "context.Context", handler.In(0).String())
+	assert.True(t, handler.IsVariadic())
+	assert.Equal(t, "func(context.Context, ...string) error", handler.Underlying().String())
+
+	middleware := types["Middleware"]
+	require.NotNil(t, middleware)
+	assert.True(t, middleware.In(0) == handler)
+	assert.True(t, middleware.Out(0) == handler)
+
+	chain := types["Chain"]
+	require.NotNil(t, chain)
+	assert.True(t, chain.Field(0).Type.Elem() == handler)
+	wrap := chain.Field(1).Type
+	require.Equal(t, 2, wrap.NumOut())
+	assert.True(t, wrap.In(0) == handler)
+	assert.Equal(t, "func(handlers.Handler, string) (handlers.Handler, bool)", wrap.String())
+}
+
+func TestLoadDir_Func(t *testing.T) {
+	types, err := LoadDir("testdata")
+	require.NoError(t, err)
+
+	handler := types["Handler"]
+	require.NotNil(t, handler)
+	assert.Equal(t, reflect.Func, handler.Kind())
+	require.Equal(t, 2, handler.NumIn())
+	assert.Equal(t, "Context", handler.In(0).Name())
+	assert.Equal(t, reflect.Slice, handler.In(1).Kind())
+	assert.Equal(t, reflect.String, handler.In(1).Elem().Kind())
+	assert.True(t, handler.IsVariadic())
+	require.Equal(t, 1, handler.NumOut())
+	assert.Equal(t, "error", handler.Out(0).Name())
+
+	route := types["Route"]
+	require.NotNil(t, route)
+	resolve := route.Field(2).Type
+	assert.Equal(t, 2, resolve.NumIn())
+	assert.Equal(t, 2, resolve.NumOut())
+	assert.True(t, resolve.Out(0).Elem() == types["Person"])
+	assert.Equal(t, "func(string, ...int) (bool, error)", route.Field(3).Type.String())
+
+	middleware := types["Middleware"]
+	require.NotNil(t, middleware)
+	assert.False(t, middleware.IsVariadic())
+	assert.True(t, middleware.In(0) == handler)
+	assert.True(t, middleware.Out(0) == handler)
+}
+
+func TestLoadDir_Chan(t *testing.T) {
+	types, err := LoadDir("testdata")
+	require.NoError(t, err)
+
+	pipeline := types["Pipeline"]
+	require.NotNil(t, pipeline)
+
+	events := pipeline.Field(0).Type
+	assert.Equal(t, reflect.Chan, events.Kind())
+	assert.Equal(t, reflect.SendDir, events.ChanDir())
+	assert.True(t, events.Elem() == types["Event"])
+
+	results := pipeline.Field(1).Type
+	assert.Equal(t, reflect.RecvDir, results.ChanDir())
+	assert.True(t, results.Elem() == types["Result"])
+
+	done := pipeline.Field(2).Type
+	assert.Equal(t, reflect.BothDir, done.ChanDir())
+	assert.Equal(t, reflect.Struct, done.Elem().Kind())
+
+	nested := pipeline.Field(3).Type
+	assert.Equal(t, reflect.BothDir, nested.ChanDir())
+	assert.Equal(t, reflect.RecvDir, nested.Elem().ChanDir())
+	assert.Equal(t, "chan (<-chan int)", nested.String())
+	assert.Equal(t, "<-chan int", nested.Elem().String())
+}
+
+func TestLoadDir_ChanString(t *testing.T) {
+	types, err := LoadDir("testdata")
+	require.NoError(t, err)
+
+	feeds, ok := types["Pipeline"].FieldByName("Feeds")
+	require.True(t, ok)
+	assert.Equal(t, "chan test.Feed", feeds.Type.String())
+	assert.Equal(t, reflect.RecvDir, types["Feed"].ChanDir())
+	assert.Equal(t, "<-chan test.Event", types["Feed"].Underlying().String())
+}
+
+func TestLoadDir_Interface(t *testing.T) {
+	types, err := LoadDir("testdata")
+	require.NoError(t, err)
+
+	maker := types["personMaker"]
+	require.NotNil(t, maker)
+	require.Equal(t, 1, maker.NumMethod())
+	assert.Equal(t, "Make", maker.Method(0).Name)
+
+	store := types["Store"]
+	require.NotNil(t, store)
+	require.Equal(t, 6, store.NumMethod())
+	var names []string
+	for i := 0; i < store.NumMethod(); i++ {
+		names = append(names, store.Method(i).Name)
+		assert.Equal(t, i, store.Method(i).Index)
+	}
+	assert.Equal(t, []string{"Close", "Get", "Put", "Read", "Write", "close"}, names)
+
+	m, found := store.MethodByName("close")
+	require.True(t, found)
+	assert.Equal(t, "github.com/alexflint/go-mold/testdata", m.PkgPath)
+
+	_, found = store.MethodByName("Missing")
+	assert.False(t, found)
+
+	// overlapping methods with identical signatures are permitted
+	closer := types["Closer"]
+	require.NotNil(t, closer)
+	assert.Equal(t, 1, closer.NumMethod())
+}
+
+func TestLoadTypes_DuplicateMethod(t *testing.T) {
+	src := `package test
+
+type A interface {
+	Foo() int
+	Foo() string
+}
+
+type B interface {
+	Bar() int
+}
+
+type C interface {
+	B
+	Bar() string
+}
+`
+	_, err := LoadTypes(strings.NewReader(src))
+	require.Error(t, err)
+
+	errs, ok := err.(ErrorList)
+	require.True(t, ok)
+	require.Len(t, errs, 2)
+	assert.Equal(t, InvalidDeclaration, errs[0].Category)
+	assert.Equal(t, "Foo", errs[0].Ident)
+	assert.Equal(t, InvalidDeclaration, errs[1].Category)
+	assert.Equal(t, "Bar", errs[1].Ident)
+}
+
+func TestLoadTypes_Redeclared(t *testing.T) {
+	src := `package test
+
+type A int
+
+type A string
+
+const N = 1
+
+const (
+	M = iota
+	N
+)
+
+func F() {}
+
+type F struct{}
+`
+	_, err := LoadTypes(strings.NewReader(src))
+	require.Error(t, err)
+
+	errs, ok := err.(ErrorList)
+	require.True(t, ok)
+	require.Len(t, errs, 3)
+	assert.Equal(t, "A redeclared in this block", errs[0].Msg)
+	assert.Equal(t, 5, errs[0].Pos.Line)
+	assert.Equal(t, InvalidDeclaration, errs[0].Category)
+	assert.Equal(t, "N redeclared in this block", errs[1].Msg)
+	assert.Equal(t, 11, errs[1].Pos.Line)
+	assert.Equal(t, "F redeclared in this block", errs[2].Msg)
+	assert.Equal(t, 16, errs[2].Pos.Line)
+}
+
+func TestLoadDir_Redeclared(t *testing.T) {
+	_, err := LoadDir("testdata/redeclared")
+	require.Error(t, err)
+
+	errs, ok := err.(ErrorList)
+	require.True(t, ok)
+	require.Len(t, errs, 3)
+	for i, name := range []string{"A", "Max", "Open"} {
+		assert.Equal(t, name+" redeclared in this block", errs[i].Msg)
+		assert.Equal(t, "b.go", filepath.Base(errs[i].Pos.Filename))
+	}
+}
+
+func TestLoadDir_Methods(t *testing.T) {
+	types, err := LoadDir("testdata")
+	require.NoError(t, err)
+
+	person := types["Person"]
+	require.NotNil(t, person)
+
+	// the method set of T contains only methods with value receivers
+	require.Equal(t, 1, person.NumMethod())
+	assert.Equal(t, "FullName", person.Method(0).Name)
+	_, found := person.MethodByName("SetName")
+	assert.False(t, found)
+	_, found = person.MethodByName("greet")
+	assert.False(t, found)
+
+	// the method set of *T contains methods with value or pointer receivers
+	personPtr := types["Route"].Field(2).Type.Out(0)
+	require.Equal(t, reflect.Ptr, personPtr.Kind())
+	require.True(t, personPtr.Elem() == person)
+	require.Equal(t, 3, personPtr.NumMethod())
+	assert.Equal(t, "Adopt", personPtr.Method(0).Name)
+	assert.Equal(t, "FullName", personPtr.Method(1).Name)
+	assert.Equal(t, "SetName", personPtr.Method(2).Name)
+
+	m, found := personPtr.MethodByName("SetName")
+	require.True(t, found)
+	assert.Equal(t, 2, m.Index)
+
+	// a named pointer type has no methods
+	assert.Equal(t, 0, types["PersonPtr"].NumMethod())
+}
+
+func TestLoadDir_MethodDescriptor(t *testing.T) {
+	types, err := LoadDir("testdata")
+	require.NoError(t, err)
+
+	person := types["Person"]
+	fullName, found := person.MethodByName("FullName")
+	require.True(t, found)
+	assert.False(t, fullName.PointerReceiver)
+	assert.Equal(t, "person_methods.go", filepath.Base(fullName.Pos.Filename))
+	assert.Equal(t, 3, fullName.Pos.Line)
+
+	// for non-interface types the receiver is the first input parameter
+	require.Equal(t, 1, fullName.Type.NumIn())
+	assert.True(t, fullName.Type.In(0) == person)
+	assert.Equal(t, reflect.String, fullName.Type.Out(0).Kind())
+
+	personPtr := types["Route"].Field(2).Type.Out(0)
+	adopt, found := personPtr.MethodByName("Adopt")
+	require.True(t, found)
+	assert.True(t, adopt.PointerReceiver)
+	require.Equal(t, 2, adopt.Type.NumIn())
+	assert.True(t, adopt.Type.In(0) == personPtr)
+	assert.True(t, adopt.Type.IsVariadic())
+
+	// for interface types there is no receiver
+	get, found := types["Store"].MethodByName("Get")
+	require.True(t, found)
+	assert.Equal(t, 1, get.Type.NumIn())
+	assert.Equal(t, 2, get.Type.NumOut())
+
+	// live types report the same descriptor
+	var s fmt.Stringer
+	str, found := TypeOf(&s).Elem().MethodByName("String")
+	require.True(t, found)
+	assert.Equal(t, "String", str.Name)
+	assert.Equal(t, reflect.String, str.Type.Out(0).Kind())
+}
+
+func TestTypeOf_PointerReceiver(t *testing.T) {
+	unmarshal, found := TypeOf(new(time.Time)).MethodByName("UnmarshalJSON")
+	require.True(t, found)
+	assert.True(t, unmarshal.PointerReceiver)
+
+	str, found := TypeOf(new(time.Time)).MethodByName("String")
+	require.True(t, found)
+	assert.False(t, str.PointerReceiver)
+
+	str, found = TypeOf(time.Time{}).MethodByName("String")
+	require.True(t, found)
+	assert.False(t, str.PointerReceiver)
+}
+
+func TestLoadDir_Generic(t *testing.T) {
+	types, err := LoadDir("testdata")
+	require.NoError(t, err)
+
+	page := types["Page"]
+	require.NotNil(t, page)
+	require.Equal(t, 1, page.NumTypeParam())
+	param := page.TypeParam(0)
+	assert.Equal(t, "T", param.Name)
+	assert.Equal(t, 0, param.Index)
+	assert.Equal(t, reflect.Interface, param.Constraint.Kind())
+	assert.Equal(t, 0, param.Constraint.NumMethod())
+
+	// the type parameter is resolved within the body
+	items := page.Field(0).Type
+	assert.Equal(t, reflect.Slice, items.Kind())
+	assert.True(t, items.Elem() == param.Type)
+	assert.Equal(t, "T", items.Elem().String())
+
+	_, found := page.MethodByName("Len")
+	assert.True(t, found)
+
+	pair := types["Pair"]
+	require.NotNil(t, pair)
+	require.Equal(t, 2, pair.NumTypeParam())
+	assert.Equal(t, "comparable", pair.TypeParam(0).Constraint.Name())
+	assert.Equal(t, "Stringer", pair.TypeParam(1).Constraint.Name())
+	assert.Equal(t, 1, pair.TypeParam(1).Type.NumMethod())
+	assert.True(t, pair.Field(1).Type == pair.TypeParam(1).Type)
+
+	list := types["List"]
+	require.NotNil(t, list)
+	assert.Equal(t, 1, list.NumTypeParam())
+	assert.True(t, list.Elem() == list.TypeParam(0).Type)
+
+	assert.Equal(t, 0, types["Person"].NumTypeParam())
+}
+
+func TestLoadTypes_GenericMethods(t *testing.T) {
+	src := `package test
+
+type Page[T any] struct {
+	Items []T
+	Next  *Page[T]
+}
+
+func (p *Page[E]) Add(item E) {}
+`
+	types, err := LoadTypes(strings.NewReader(src))
+	require.NoError(t, err)
+
+	page := types["Page"]
+	require.NotNil(t, page)
+	param := page.TypeParam(0)
+
+	// within its own declaration, Page[T] is the generic type itself
+	ptr := page.Field(1).Type
+	assert.True(t, ptr.Elem() == page)
+
+	// the type parameter is resolved within methods, even under a
+	// different name
+	add, found := ptr.MethodByName("Add")
+	require.True(t, found)
+	assert.True(t, add.Type.In(1) == param.Type)
+}
+
+func TestLoadDir_Instantiate(t *testing.T) {
+	types, err := LoadDir("testdata")
+	require.NoError(t, err)
+
+	cache := types["Cache"]
+	require.NotNil(t, cache)
+
+	items := cache.Field(0).Type
+	assert.Equal(t, reflect.Struct, items.Kind())
+	assert.Equal(t, "Page[test.User]", items.Name())
+	assert.Equal(t, "test.Page[test.User]", items.String())
+	assert.Equal(t, 0, items.NumTypeParam())
+	assert.True(t, items.Field(0).Type.Elem() == types["User"])
+	assert.Equal(t, reflect.Int, items.Field(1).Type.Kind())
+
+	// identical instantiations are the same type
+	assert.True(t, cache.Field(2).Type == items)
+
+	// methods are instantiated along with the type
+	_, found := items.MethodByName("Len")
+	assert.True(t, found)
+
+	result := cache.Field(1).Type.Elem()
+	assert.Equal(t, "test.Outcome[int,error]", result.String())
+	assert.Equal(t, reflect.Int, result.Field(0).Type.Kind())
+	assert.Equal(t, "error", result.Field(1).Type.String())
+
+	users := cache.Field(3).Type
+	assert.Equal(t, reflect.Slice, users.Kind())
+	assert.Equal(t, "test.List[*test.User]", users.String())
+
+	// recursive generic types refer to their own instantiation
+	node := cache.Field(4).Type.Elem()
+	assert.Equal(t, "test.Node[int]", node.String())
+	assert.True(t, node.Field(1).Type.Elem() == node)
+}
+
+func TestLoadTypes_InstantiateMethods(t *testing.T) {
+	src := `package test
+
+type Page[T any] struct {
+	Items []T
+}
+
+func (p *Page[T]) Add(item T) {}
+
+type User struct{}
+
+type Cache struct {
+	Users *Page[User]
+}
+`
+	types, err := LoadTypes(strings.NewReader(src))
+	require.NoError(t, err)
+
+	// methods with pointer receivers are instantiated along with the type
+	users := types["Cache"].Field(0).Type
+	assert.Equal(t, "*test.Page[test.User]", users.String())
+	add, found := users.MethodByName("Add")
+	require.True(t, found)
+	assert.True(t, add.Type.In(0) == users)
+	assert.True(t, add.Type.In(1) == types["User"])
+}
+
+func TestLoadDir_Constraint(t *testing.T) {
+	types, err := LoadDir("testdata")
+	require.NoError(t, err)
+
+	number := types["Number"]
+	require.NotNil(t, number)
+	assert.False(t, number.IsMethodSet())
+	require.Equal(t, 3, number.NumTerm())
+	assert.True(t, number.Term(0).Tilde)
+	assert.Equal(t, "int", number.Term(0).Type.String())
+	assert.Equal(t, "float64", number.Term(2).Type.String())
+
+	assert.True(t, number.Satisfies(TypeOf(0)))
+	assert.True(t, number.Satisfies(TypeOf(time.Duration(0))))
+	assert.False(t, number.Satisfies(TypeOf("")))
+	assert.False(t, number.Satisfies(types["Label"]))
+
+	// embedded elements are intersected
+	integer := types["Integer"]
+	require.NotNil(t, integer)
+	require.Equal(t, 2, integer.NumTerm())
+	assert.Equal(t, "int64", integer.Term(1).Type.String())
+	assert.False(t, integer.Satisfies(TypeOf(1.5)))
+
+	// methods and terms must both be satisfied
+	stringish := types["Stringish"]
+	require.NotNil(t, stringish)
+	assert.False(t, stringish.IsMethodSet())
+	assert.Equal(t, 1, stringish.NumMethod())
+	assert.True(t, stringish.Satisfies(types["Label"]))
+	assert.False(t, stringish.Satisfies(TypeOf("")))
+
+	key := types["Key"]
+	require.NotNil(t, key)
+	assert.False(t, key.IsMethodSet())
+	assert.Equal(t, 0, key.NumTerm())
+	assert.True(t, key.Satisfies(types["Window"]))
+	assert.False(t, key.Satisfies(types["Family"]))
+
+	// ordinary interfaces are method sets
+	assert.True(t, types["Store"].IsMethodSet())
+	assert.True(t, types["Closer"].Satisfies(TypeOf(new(os.File))))
+
+	// type parameters carry the type set of their constraint
+	stats := types["Stats"]
+	require.NotNil(t, stats)
+	assert.Equal(t, 3, stats.TypeParam(0).Type.NumTerm())
+	assert.True(t, number.Satisfies(stats.TypeParam(0).Type))
+	assert.False(t, integer.Satisfies(stats.TypeParam(0).Type))
+
+	// constraints that are not interfaces are implicitly wrapped in one
+	series := types["Series"]
+	require.NotNil(t, series)
+	constraint := series.TypeParam(0).Constraint
+	assert.Equal(t, reflect.Interface, constraint.Kind())
+	assert.Equal(t, 2, constraint.NumTerm())
+	assert.True(t, number.Satisfies(series.TypeParam(0).Type))
+}
+
+func TestLoadTypes_EmptyTypeSet(t *testing.T) {
+	src := `package test
+
+type Number interface{ ~int | ~float64 }
+
+type Never interface {
+	int
+	string
+}
+
+type Disjoint interface {
+	Number
+	~string
+}
+
+type Empty[T Never] struct{}
+
+type Keys interface {
+	comparable
+	~int | []int
+}
+
+type NoKeys interface {
+	comparable
+	[]int | map[int]int
+}
+`
+	types, err := LoadTypes(strings.NewReader(src))
+	require.NoError(t, err)
+
+	never := types["Never"]
+	require.NotNil(t, never)
+	assert.True(t, never.IsEmptyTypeSet())
+	assert.False(t, never.IsMethodSet())
+	assert.Equal(t, 0, never.NumTerm())
+	assert.False(t, never.Satisfies(TypeOf(0)))
+	assert.False(t, never.Satisfies(TypeOf("")))
+	assert.Equal(t, "interface { int; string }", never.Underlying().String())
+
+	disjoint := types["Disjoint"]
+	require.NotNil(t, disjoint)
+	assert.True(t, disjoint.IsEmptyTypeSet())
+	assert.Equal(t, "interface { test.Number; ~string }", disjoint.Underlying().String())
+
+	// a type parameter with an empty type set satisfies nothing either
+	param := types["Empty"].TypeParam(0).Type
+	assert.True(t, param.IsEmptyTypeSet())
+	assert.False(t, never.Satisfies(param))
+
+	// comparable removes the terms that are not comparable
+	keys := types["Keys"]
+	require.NotNil(t, keys)
+	require.Equal(t, 1, keys.NumTerm())
+	assert.Equal(t, TypeOf(0), keys.Term(0).Type)
+	assert.False(t, keys.Satisfies(TypeOf([]int{})))
+	assert.True(t, types["NoKeys"].IsEmptyTypeSet())
+
+	assert.False(t, types["Number"].IsEmptyTypeSet())
+	assert.False(t, TypeOf(new(error)).Elem().IsEmptyTypeSet())
+}
+
+func TestLoadTypes_ConstraintErrors(t *testing.T) {
+	src := `package test
+
+type Celsius float64
+
+type Stringer interface {
+	String() string
+}
+
+type A interface {
+	~Celsius
+}
+
+type B interface {
+	int | Stringer
+}
+`
+	_, err := LoadTypes(strings.NewReader(src))
+	require.Error(t, err)
+
+	errs, ok := err.(ErrorList)
+	require.True(t, ok)
+	require.Len(t, errs, 2)
+	assert.Contains(t, errs[0].Msg, "invalid use of ~")
+	assert.Contains(t, errs[1].Msg, "test.Stringer in union")
+}
+
+func TestLoadPackage_Alias(t *testing.T) {
+	pkg, err := LoadPackage("testdata")
+	require.NoError(t, err)
+	types := pkg.Types
+
+	// an alias denotes the same type as its target
+	assert.True(t, pkg.IsAlias("Human"))
+	assert.True(t, types["Human"] == types["Person"])
+	assert.Equal(t, "Person", types["Human"].Name())
+	assert.True(t, pkg.IsAlias("Contacts"))
+	assert.Equal(t, reflect.Map, types["Contacts"].Kind())
+	assert.Equal(t, "", types["Contacts"].Name())
+	assert.True(t, identical(types["Timestamp"], TypeOf(time.Time{})))
+
+	// methods declared through an alias belong to the target
+	temp := types["Temperature"]
+	assert.True(t, temp == types["Celsius"])
+	assert.Equal(t, 2, temp.NumMethod())
+	_, found := temp.MethodByName("Kelvin")
+	assert.True(t, found)
+
+	// a defined type has the underlying type of its target but no methods
+	assert.False(t, pkg.IsAlias("Employee"))
+	employee := types["Employee"]
+	require.NotNil(t, employee)
+	assert.Equal(t, "Employee", employee.Name())
+	assert.Equal(t, reflect.Struct, employee.Kind())
+	assert.Equal(t, 5, employee.NumField())
+	assert.Equal(t, 0, employee.NumMethod())
+	assert.False(t, identical(employee, types["Person"]))
+	assert.True(t, identical(employee.Underlying(), types["Person"].Underlying()))
+	assert.Equal(t, "", employee.Underlying().Name())
+
+	moment := types["Moment"]
+	assert.Equal(t, reflect.Struct, moment.Kind())
+	assert.Equal(t, 0, moment.NumMethod())
+
+	reading := types["Reading"]
+	assert.Equal(t, 0, reading.NumMethod())
+	assert.True(t, reading.Underlying() == TypeOf(float64(0)))
+	assert.True(t, types["Celsius"].Underlying() == TypeOf(float64(0)))
+	assert.True(t, TypeOf(time.Duration(0)).Underlying() == TypeOf(int64(0)))
+}
+
+func TestLoadDir_StructFields(t *testing.T) {
+	types, err := LoadDir("testdata")
+	require.NoError(t, err)
+
+	record := types["Record"]
+	require.NotNil(t, record)
+
+	// the same struct, compiled
+	var compiled struct {
+		Event  *int
+		Flag   bool
+		ID     int64
+		Name   string
+		small  int8
+		Values []float32
+		Tags   map[string]string
+		Ratio  complex128
+		Nested struct {
+			A byte
+			B int32
+		}
+		Arr   [3]int16
+		Empty struct{}
+	}
+	live := reflect.TypeOf(compiled)
+	require.Equal(t, live.NumField(), record.NumField())
+	for i := 0; i < live.NumField(); i++ {
+		f := record.Field(i)
+		assert.Equal(t, live.Field(i).Name, f.Name)
+		assert.Equal(t, live.Field(i).Offset, f.Offset, f.Name)
+		assert.Equal(t, []int{i}, f.Index)
+	}
+	assert.True(t, record.Field(0).Anonymous)
+	assert.Equal(t, "", record.Field(0).PkgPath)
+	assert.Equal(t, "github.com/alexflint/go-mold/testdata", record.Field(4).PkgPath)
+	assert.Equal(t, live.Field(8).Type.Field(1).Offset, record.Field(8).Type.Field(1).Offset)
+
+	person := types["Person"]
+	assert.Equal(t, "", person.Field(0).PkgPath)
+	assert.Equal(t, "github.com/alexflint/go-mold/testdata", person.Field(3).PkgPath)
+
+	// the underlying type of a named struct has the same layout
+	assert.Equal(t, record.Field(9).Offset, record.Underlying().Field(9).Offset)
+}
+
+func TestLoadDir_Size(t *testing.T) {
+	types, err := LoadDir("testdata", WithArch("amd64"))
+	require.NoError(t, err)
+
+	record := types["Record"]
+	assert.Equal(t, uintptr(112), record.Size())
+	assert.Equal(t, 8, record.Align())
+	assert.Equal(t, 8, record.FieldAlign())
+	assert.Equal(t, uintptr(8), record.Field(8).Type.Size())
+	assert.Equal(t, 4, record.Field(8).Type.Align())
+	assert.Equal(t, uintptr(6), record.Field(9).Type.Size())
+	assert.Equal(t, uintptr(0), record.Field(10).Type.Size())
+
+	assert.Equal(t, uintptr(80), types["Person"].Size())
+	assert.Equal(t, uintptr(800), types["PersonArray"].Size())
+	assert.Equal(t, uintptr(24), types["PersonSlice"].Size())
+	assert.Equal(t, uintptr(8), types["AddressBook"].Size())
+	assert.Equal(t, uintptr(8), types["Celsius"].Size())
+	assert.Equal(t, uintptr(16), types["Store"].Size())
+
+	// a struct ending in a zero-sized field is padded
+	empty := types["Profile"].Field(4).Type
+	assert.Equal(t, uintptr(0), empty.Size())
+	assert.Equal(t, 1, empty.Align())
+
+	types, err = LoadDir("testdata", WithArch("386"))
+	require.NoError(t, err)
+	record = types["Record"]
+	assert.Equal(t, uintptr(76), record.Size())
+	assert.Equal(t, 4, record.Align())
+	assert.Equal(t, uintptr(16), record.Field(3).Offset)
+	assert.Equal(t, uintptr(44), record.Field(7).Offset)
+	assert.Equal(t, uintptr(12), record.Field(5).Type.Size())
+	assert.Equal(t, uintptr(12), types["PersonSlice"].Size())
+
+	_, err = LoadDir("testdata", WithArch("pdp11"))
+	assert.Error(t, err)
+}
+
+func TestLoadTypes_SizeDefined(t *testing.T) {
+	src := `package test
+
+type X int
+type U uintptr
+type P *X
+
+type T struct {
+	A bool
+	X X
+}
+`
+	types, err := LoadTypes(strings.NewReader(src), WithArch("386"))
+	require.NoError(t, err)
+	for _, name := range []string{"X", "U", "P"} {
+		assert.Equal(t, uintptr(4), types[name].Size(), name)
+		assert.Equal(t, 4, types[name].Align(), name)
+		assert.Equal(t, 4, types[name].FieldAlign(), name)
+	}
+	assert.Equal(t, uintptr(8), types["T"].Size())
+	assert.Equal(t, uintptr(4), types["T"].Field(1).Offset)
+
+	types, err = LoadTypes(strings.NewReader(src), WithArch("amd64"))
+	require.NoError(t, err)
+	assert.Equal(t, uintptr(8), types["X"].Size())
+	assert.Equal(t, 8, types["U"].Align())
+}
+
+func TestLoadTypes_SizeRecursive(t *testing.T) {
+	src := `package test
+
+type T struct {
+	t T
+	x int64
+}
+
+type A struct {
+	b B
+	y int32
+}
+
+type B struct {
+	a [2]A
+}
+`
+	for _, arch := range []string{"amd64", "386"} {
+		_, err := LoadTypes(strings.NewReader(src), WithArch(arch))
+		require.Error(t, err)
+		errs, ok := err.(ErrorList)
+		require.True(t, ok)
+		require.Len(t, errs, 2)
+		assert.Contains(t, errs[0].Msg, "invalid recursive type T")
+		assert.Contains(t, errs[1].Msg, "invalid recursive type A")
+	}
+}
+
+func TestLoadTypes_LayoutDuringMethods(t *testing.T) {
+	// the layout of S depends on T, whose underlying type is not known
+	// while the methods of U are being resolved, and the order in which
+	// declarations are populated varies from one load to the next
+	src := `package p
+
+type T U
+
+type U struct{ x int }
+
+func (U) M(s S) {}
+
+type S struct{ t T }
+`
+	for i := 0; i < 50; i++ {
+		types, err := LoadTypes(strings.NewReader(src))
+		require.NoError(t, err)
+		assert.Equal(t, unsafe.Sizeof(int(0)), types["S"].Size())
+	}
+}
+
+func TestLayout(t *testing.T) {
+	types, err := LoadDir("testdata", WithArch("amd64"))
+	require.NoError(t, err)
+
+	layout := Layout(types["Record"])
+	assert.Equal(t, uintptr(112), layout.Size)
+	assert.Equal(t, 8, layout.Align)
+	assert.Equal(t, uintptr(16), layout.Padding)
+	require.Len(t, layout.Fields, 11)
+	assert.Equal(t, "Flag", layout.Fields[1].Name)
+	assert.Equal(t, uintptr(8), layout.Fields[1].Offset)
+	assert.Equal(t, uintptr(1), layout.Fields[1].Size)
+	assert.Equal(t, uintptr(7), layout.Fields[1].Padding)
+	assert.Equal(t, uintptr(2), layout.Fields[10].Padding)
+
+	assert.Equal(t, uintptr(96), layout.OptimalSize)
+	assert.Equal(t, []int{10, 0, 2, 3, 5, 6, 7, 8, 9, 1, 4}, layout.OptimalOrder)
+
+	// a struct without padding keeps its order
+	layout = Layout(types["Person"])
+	assert.Equal(t, layout.Size, layout.OptimalSize)
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, layout.OptimalOrder)
+
+	// layouts are computed for the architecture the types were loaded for
+	types, err = LoadDir("testdata", WithArch("386"))
+	require.NoError(t, err)
+	layout = Layout(types["Record"])
+	assert.Equal(t, uintptr(76), layout.Size)
+	assert.Equal(t, uintptr(4), layout.Fields[1].Offset)
+	assert.Equal(t, uintptr(3), layout.Fields[1].Padding)
+
+	// and for the host for live types
+	live := Layout(TypeOf(struct {
+		A bool
+		B *int
+		C bool
+	}{}))
+	word := unsafe.Sizeof(uintptr(0))
+	assert.Equal(t, 3*word, live.Size)
+	assert.Equal(t, 2*word, live.OptimalSize)
+	assert.Equal(t, []int{1, 0, 2}, live.OptimalOrder)
+}
+
+func TestLoadDir_FieldByName(t *testing.T) {
+	types, err := LoadDir("testdata")
+	require.NoError(t, err)
+
+	// the same structs, compiled
+	type Base struct {
+		ID   int
+		Name string
+	}
+	type Audit struct {
+		ID int
+		By string
+	}
+	type Document struct {
+		Base
+		*Audit
+		Title string
+	}
+	type Twice struct {
+		Base
+	}
+	type Archive struct {
+		Document
+		Name string
+		Twice
+	}
+
+	for _, name := range []string{"Document", "Archive"} {
+		static := types[name]
+		require.NotNil(t, static)
+		live := TypeOf(Document{})
+		if name == "Archive" {
+			live = TypeOf(Archive{})
+		}
+		for _, field := range []string{"ID", "Name", "By", "Title", "Base", "Audit", "Missing"} {
+			want, wantOK := live.FieldByName(field)
+			got, gotOK := static.FieldByName(field)
+			assert.Equal(t, wantOK, gotOK, "%s.%s", name, field)
+			assert.Equal(t, want.Index, got.Index, "%s.%s", name, field)
+			assert.Equal(t, want.Name, got.Name, "%s.%s", name, field)
+		}
+	}
+
+	// promoted through a pointer
+	doc := types["Document"]
+	by, found := doc.FieldByName("By")
+	require.True(t, found)
+	assert.Equal(t, []int{1, 1}, by.Index)
+	assert.Equal(t, "By", doc.FieldByIndex([]int{1, 1}).Name)
+	assert.Equal(t, []int{1, 1}, doc.FieldByIndex([]int{1, 1}).Index)
+
+	// ID is ambiguous at depth one
+	_, found = doc.FieldByName("ID")
+	assert.False(t, found)
+
+	title, found := types["Archive"].FieldByNameFunc(func(s string) bool {
+		return strings.HasPrefix(s, "Ti")
+	})
+	require.True(t, found)
+	assert.Equal(t, []int{0, 2}, title.Index)
+
+	// defined types share the fields of their underlying type
+	employee := types["Employee"]
+	age, found := employee.FieldByName("Age")
+	require.True(t, found)
+	assert.Equal(t, []int{1}, age.Index)
+}
+
+func TestLoadDir_PromotedMethods(t *testing.T) {
+	types, err := LoadDir("testdata")
+	require.NoError(t, err)
+
+	methodNames := func(t Type) []string {
+		var names []string
+		for i := 0; i < t.NumMethod(); i++ {
+			names = append(names, t.Method(i).Name)
+		}
+		return names
+	}
+
+	// Describe is ambiguous between Base and Audit, and Rename needs a
+	// pointer to the embedded Base
+	doc := types["Document"]
+	assert.Equal(t, []string{"Sign"}, methodNames(doc))
+
+	sign, found := doc.MethodByName("Sign")
+	require.True(t, found)
+	assert.True(t, sign.PointerReceiver)
+	assert.True(t, sign.Type.In(0) == doc)
+
+	// methods are promoted through pointers and interfaces
+	service := types["Service"]
+	assert.Equal(t, []string{"Describe", "Log", "Rename"}, methodNames(service))
+	assert.True(t, implements(service, types["Describer"]))
+	assert.True(t, implements(service, types["Logger"]))
+	assert.False(t, implements(doc, types["Describer"]))
+
+	// the Name field of Archive shadows nothing, but Base is reachable
+	// twice at the same depth
+	archive := types["Archive"]
+	assert.Equal(t, []string{"Sign"}, methodNames(archive))
+
+	// defined types keep promoted methods but not declared ones
+	assert.Equal(t, []string{"Describe", "Log", "Rename"}, methodNames(types["Renamed"]))
+	assert.Equal(t, 0, types["Employee"].NumMethod())
+}
+
+func TestLoadTypes_PromotedPointerMethods(t *testing.T) {
+	src := `package test
+
+type Base struct{ Name string }
+
+func (b Base) Describe() string    { return b.Name }
+func (b *Base) Rename(name string) { b.Name = name }
+
+type Audit struct{ By string }
+
+func (a Audit) Describe() string { return a.By }
+func (a *Audit) Sign() string    { return a.By }
+
+type Document struct {
+	Base
+	*Audit
+}
+
+type Refs struct {
+	Doc *Document
+}
+`
+	types, err := LoadTypes(strings.NewReader(src))
+	require.NoError(t, err)
+
+	// Describe is ambiguous between Base and Audit, and the method set of
+	// *Document includes Rename through the addressable Base
+	ptr := types["Refs"].Field(0).Type
+	var names []string
+	for i := 0; i < ptr.NumMethod(); i++ {
+		names = append(names, ptr.Method(i).Name)
+	}
+	assert.Equal(t, []string{"Rename", "Sign"}, names)
+}
+
+func TestLoadDir_Relations(t *testing.T) {
+	types, err := LoadDir("testdata")
+	require.NoError(t, err)
+
+	stringer := TypeOf((*fmt.Stringer)(nil)).Elem()
+	errorType := TypeOf((*error)(nil)).Elem()
+	label, person := types["Label"], types["Person"]
+
+	// static types against live interfaces
+	assert.True(t, label.Implements(stringer))
+	assert.False(t, person.Implements(stringer))
+	assert.False(t, label.Implements(errorType))
+	assert.True(t, label.AssignableTo(stringer))
+	assert.True(t, types["Celsius"].Implements(stringer))
+
+	// static types against static interfaces
+	assert.True(t, types["Service"].Implements(types["Describer"]))
+	assert.True(t, types["Service"].AssignableTo(types["Logger"]))
+	assert.False(t, types["Document"].Implements(types["Describer"]))
+	assert.True(t, types["Store"].Implements(types["Closer"]))
+	assert.True(t, types["Store"].AssignableTo(types["Closer"]))
+	assert.False(t, types["Closer"].AssignableTo(types["Store"]))
+
+	// live types against static interfaces
+	assert.False(t, TypeOf(time.Second).Implements(types["Stringish"]))
+	assert.True(t, TypeOf(new(os.File)).Implements(types["Closer"]))
+	assert.True(t, TypeOf(new(os.File)).AssignableTo(types["Closer"]))
+	assert.True(t, TypeOf(0).Implements(types["Number"]))
+
+	// identical underlying types are assignable only if one is unnamed
+	employee := types["Employee"]
+	assert.False(t, employee.AssignableTo(person))
+	assert.True(t, employee.ConvertibleTo(person))
+	assert.True(t, person.ConvertibleTo(employee))
+	assert.False(t, types["Record"].ConvertibleTo(person))
+
+	float64Type := TypeOf(float64(0))
+	assert.False(t, types["Celsius"].AssignableTo(float64Type))
+	assert.True(t, types["Celsius"].ConvertibleTo(float64Type))
+	assert.True(t, float64Type.ConvertibleTo(types["Celsius"]))
+	assert.True(t, types["Celsius"].ConvertibleTo(TypeOf(0)))
+	assert.False(t, types["Celsius"].ConvertibleTo(TypeOf("")))
+
+	// strings, bytes and runes
+	assert.True(t, label.ConvertibleTo(TypeOf([]byte(nil))))
+	assert.True(t, TypeOf([]rune(nil)).ConvertibleTo(label))
+	assert.True(t, TypeOf(0).ConvertibleTo(label))
+	assert.False(t, TypeOf(1.5).ConvertibleTo(label))
+
+	// a bidirectional channel is assignable to a directional one
+	pipeline := types["Pipeline"]
+	done := pipeline.Field(2).Type
+	assert.True(t, done.AssignableTo(TypeOf(make(<-chan struct{}))))
+	assert.False(t, pipeline.Field(1).Type.AssignableTo(done))
+
+	assert.Panics(t, func() { label.Implements(person) })
+}
+
+func TestLoadTypes_UnnamedRelations(t *testing.T) {
+	src := `package test
+
+type Label string
+
+func (l Label) String() string { return string(l) }
+
+type Person struct{ Name string }
+
+type People []Person
+
+type Crowd [2]Person
+
+type Refs struct {
+	Label  *Label
+	People []Person
+	Crowd  *Crowd
+}
+`
+	types, err := LoadTypes(strings.NewReader(src))
+	require.NoError(t, err)
+	refs := types["Refs"]
+
+	stringer := TypeOf((*fmt.Stringer)(nil)).Elem()
+	assert.True(t, refs.Field(0).Type.Implements(stringer))
+
+	// a named and an unnamed type with identical underlying types are
+	// assignable in both directions
+	people := refs.Field(1).Type
+	assert.True(t, types["People"].AssignableTo(people))
+	assert.True(t, people.AssignableTo(types["People"]))
+
+	// slices convert to arrays and array pointers
+	assert.True(t, people.ConvertibleTo(types["Crowd"]))
+	assert.True(t, people.ConvertibleTo(refs.Field(2).Type))
+	assert.False(t, people.AssignableTo(types["Crowd"]))
+}
+
+func TestLoadDir_Comparable(t *testing.T) {
+	types, err := LoadDir("testdata")
+	require.NoError(t, err)
+
+	for _, name := range []string{"Coord", "Board", "Label", "Celsius", "Closer", "Moment"} {
+		assert.True(t, types[name].Comparable(), name)
+		assert.NoError(t, ComparableError(types[name]), name)
+	}
+	assert.True(t, types["Pair"].TypeParam(0).Type.Comparable())
+
+	incomparable := map[string]string{
+		"Person":   "test.Person.Children is a slice",
+		"Shelf":    "test.Shelf[i].Tags is a map",
+		"Callback": "test.Callback.Run is a func",
+		"Pair":     "test.Pair.Value is a type parameter that is not constrained to be comparable",
+		"Grid":     "test.Grid[i] is a type parameter that permits []int, which is not comparable",
+	}
+	for name, reason := range incomparable {
+		assert.False(t, types[name].Comparable(), name)
+		assert.EqualError(t, ComparableError(types[name]), reason, name)
+	}
+
+	// live and static types agree
+	assert.Equal(t, TypeOf(struct{ A []int }{}).Comparable(), types["Person"].Comparable())
+	assert.EqualError(t, ComparableError(TypeOf(map[string]int{})), "map[string]int is a map")
+}
+
+func TestLoadTypes_UnnamedComparable(t *testing.T) {
+	src := `package test
+
+type Person struct{ Name string }
+
+type Refs struct {
+	Pairs  [2][]int
+	People [3]Person
+}
+`
+	types, err := LoadTypes(strings.NewReader(src))
+	require.NoError(t, err)
+	refs := types["Refs"]
+
+	pairs := refs.Field(0).Type
+	assert.False(t, pairs.Comparable())
+	assert.EqualError(t, ComparableError(pairs), "[2][]int[i] is a slice")
+	assert.True(t, refs.Field(1).Type.Comparable())
+	assert.EqualError(t, ComparableError(refs), "test.Refs.Pairs[i] is a slice")
+}
+
+func TestLoadDir_String(t *testing.T) {
+	types, err := LoadDir("testdata")
+	require.NoError(t, err)
+
+	profile := types["Profile"]
+	require.NotNil(t, profile)
+	assert.Equal(t, "test.Profile", profile.String())
+	assert.Equal(t, "test.Person", profile.Field(0).Type.String())
+	assert.Equal(t, StructTag(`json:"handle"`), profile.Field(1).Tag)
+	assert.Equal(t, "handle", profile.Field(1).Tag.Get("json"))
+	assert.Equal(t, `struct { Street string "json:\"street,omitempty\""; City string }`,
+		profile.Field(2).Type.String())
+	assert.Equal(t, "interface { Make() test.Person; close() error }", profile.Field(3).Type.String())
+	assert.Equal(t, "struct {}", profile.Field(4).Type.String())
+	assert.Equal(t, "interface {}", profile.Field(5).Type.String())
+
+	assert.Equal(t, `struct { test.Person; Handle string "json:\"handle\""; Address struct { Street string "json:\"street,omitempty\""; City string }; Maker interface { Make() test.Person; close() error }; Empty struct {}; Any interface {} }`,
+		profile.Underlying().String())
+	assert.Equal(t, "test.Employee", types["Employee"].String())
+	assert.Equal(t, "float64", types["Celsius"].Underlying().String())
+
+	assert.Equal(t, "interface { ~int | ~int64 | ~float64 }", types["Number"].Underlying().String())
+	assert.Equal(t, "interface { ~string; String() string }", types["Stringish"].Underlying().String())
+	assert.Equal(t, "interface { comparable }", types["Key"].Underlying().String())
+}
+
+func TestLoadTypes_NamedString(t *testing.T) {
+	src := `package test
+
+type Ptr *int
+type Slice []string
+type Array [2]bool
+type Map map[string]int
+type Chan chan<- error
+type Func func(int) string
+type Alias = Map
+
+type T struct {
+	P Ptr
+	S []Slice
+	M map[Array]Map
+	C *Chan
+	F Func
+	A Alias
+}
+`
+	types, err := LoadTypes(strings.NewReader(src))
+	require.NoError(t, err)
+
+	for _, name := range []string{"Ptr", "Slice", "Array", "Map", "Chan", "Func"} {
+		assert.Equal(t, "test."+name, types[name].String())
+	}
+	assert.Equal(t, "*int", types["Ptr"].Underlying().String())
+	assert.Equal(t, "[]string", types["Slice"].Underlying().String())
+	assert.Equal(t, "[2]bool", types["Array"].Underlying().String())
+	assert.Equal(t, "map[string]int", types["Map"].Underlying().String())
+	assert.Equal(t, "chan<- error", types["Chan"].Underlying().String())
+	assert.Equal(t, "func(int) string", types["Func"].Underlying().String())
+	assert.Equal(t, "test.Map", types["Alias"].String())
+
+	assert.Equal(t, "struct { P test.Ptr; S []test.Slice; M map[test.Array]test.Map; C *test.Chan; F test.Func; A test.Map }",
+		types["T"].Underlying().String())
+}
+
+func TestLoadTypes_AliasErrors(t *testing.T) {
+	src := `package test
+
+type A = B
+
+type B = A
+
+type C D
+
+type D C
+`
+	_, err := LoadTypes(strings.NewReader(src))
+	require.Error(t, err)
+
+	errs, ok := err.(ErrorList)
+	require.True(t, ok)
+	require.Len(t, errs, 2)
+	assert.Contains(t, errs[0].Msg, "invalid recursive type alias")
+	assert.Contains(t, errs[1].Msg, "invalid recursive type")
+}
+
+func TestLoadDir_ArrayLength(t *testing.T) {
+	types, err := LoadDir("testdata")
+	require.NoError(t, err)
+
+	buffer := types["Buffer"]
+	lengths := make(map[string]int)
+	for i := 0; i < buffer.NumField(); i++ {
+		f := buffer.Field(i)
+		lengths[f.Name] = f.Type.Len()
+	}
+	assert.Equal(t, map[string]int{
+		"Header": 16,
+		"Digest": 32,
+		"Pages":  2,
+		"Chunks": 4,
+		"Flags":  2,
+		"Primes": 4,
+		"Table":  4,
+		"Name":   4,
+		"Raw":    8,
+		"Octal":  8,
+	}, lengths)
+}
+
+func TestLoadPackage_Consts(t *testing.T) {
+	pkg, err := LoadPackage("testdata")
+	require.NoError(t, err)
+
+	red := pkg.Consts["Red"]
+	require.NotNil(t, red)
+	assert.Equal(t, pkg.Types["Color"], red.Type)
+	assert.False(t, red.Untyped)
+	assert.Equal(t, constant.MakeInt64(1), red.Value)
+	assert.Equal(t, "Red is the color of blood\n", red.Doc)
+	assert.Equal(t, 7, red.Pos.Line)
+	assert.Equal(t, "", pkg.Consts["Blue"].Doc)
+
+	greeting := pkg.Consts["Greeting"]
+	assert.True(t, greeting.Untyped)
+	assert.Equal(t, reflect.String, greeting.Type.Kind())
+	assert.Equal(t, `"hello"`, greeting.Value.String())
+	assert.Equal(t, "Greeting is the default greeting\n", greeting.Doc)
+
+	tau := pkg.Consts["Tau"]
+	assert.True(t, tau.Untyped)
+	assert.Equal(t, reflect.Float64, tau.Type.Kind())
+	assert.Equal(t, "6.28318", tau.Value.String())
+
+	assert.Equal(t, constant.MakeInt64(1<<20), pkg.Consts["MB"].Value)
+	assert.NotContains(t, pkg.Consts, "_")
+}
+
+func TestLoadPackage_ConstErrors(t *testing.T) {
+	dir := filepath.Join("testdata", "builtins")
+	types, err := LoadDir(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 3, types["T"].Field(0).Type.Len())
+
+	pkg, err := LoadPackage(dir)
+	require.NoError(t, err)
+	for _, name := range []string{"C", "M", "O", "P", "D", "K"} {
+		assert.NotContains(t, pkg.Consts, name)
+	}
+	assert.Equal(t, constant.MakeInt64(3), pkg.Consts["N"].Value)
+
+	// the import failure is reported at the import, with a message that
+	// depends on the environment
+	require.Len(t, pkg.Errors, 5)
+	assert.Equal(t, ImportFailed, pkg.Errors[0].Category)
+	assert.Equal(t, 6, pkg.Errors[0].Pos.Line)
+
+	var msgs []string
+	for _, e := range pkg.Errors[1:] {
+		msgs = append(msgs, fmt.Sprintf("%d:%d: %v: %s", e.Pos.Line, e.Pos.Column, e.Category, e.Msg))
+	}
+	assert.Equal(t, []string{
+		"16:6: invalid constant: complex is not supported in constant expressions",
+		"17:6: invalid constant: max is not supported in constant expressions",
+		"18:6: invalid constant: unsafe.Offsetof is not supported in constant expressions",
+		"24:4: unknown identifier: unknown type: Undef",
+	}, msgs)
+}
+
+func TestLoadPackage_Enum(t *testing.T) {
+	pkg, err := LoadPackage("testdata")
+	require.NoError(t, err)
+
+	enumValues := func(name string) map[string]int64 {
+		enum := pkg.Enum(name)
+		require.NotNil(t, enum, name)
+		assert.Equal(t, pkg.Types[name], enum.Type)
+		values := make(map[string]int64)
+		for _, c := range enum.Values() {
+			n, _ := constant.Int64Val(c.Value)
+			values[c.Name] = n
+		}
+		return values
+	}
+
+	assert.Equal(t, map[string]int64{"Red": 1, "Green": 2, "Blue": 3}, enumValues("Color"))
+	assert.Equal(t, map[string]int64{"Monday": 1, "Tuesday": 2}, enumValues("Weekday"))
+	assert.Equal(t, map[string]int64{"Small": 1, "Medium": 2, "Large": 4}, enumValues("Size"))
+
+	var names []string
+	for _, c := range pkg.Enum("Color").Values() {
+		names = append(names, c.Name)
+	}
+	assert.Equal(t, []string{"Red", "Green", "Blue"}, names)
+
+	// untyped constants and groups that do not use iota are not enums
+	assert.Nil(t, pkg.Enum("Person"))
+	assert.Nil(t, pkg.Enum("int"))
+}
+
+func TestLoadPackage_Funcs(t *testing.T) {
+	pkg, err := LoadPackage("testdata")
+	require.NoError(t, err)
+	assert.NotContains(t, pkg.Funcs, "init")
+
+	newPerson := pkg.Funcs["NewPerson"]
+	require.NotNil(t, newPerson)
+	assert.Equal(t, "NewPerson", newPerson.Type.Name())
+	assert.Equal(t, pkg.Path, newPerson.Type.PkgPath())
+	assert.Equal(t, reflect.Func, newPerson.Type.Kind())
+	assert.Equal(t, []string{"name", "age"}, newPerson.Params)
+	assert.Equal(t, []string{""}, newPerson.Results)
+	assert.Equal(t, "NewPerson creates a person with the given name\n", newPerson.Doc)
+	assert.Equal(t, 6, newPerson.Pos.Line)
+	require.Equal(t, 1, newPerson.Type.NumOut())
+	assert.Equal(t, pkg.Types["Person"], newPerson.Type.Out(0).Elem())
+
+	greet := pkg.Funcs["Greet"]
+	require.NotNil(t, greet)
+	assert.True(t, greet.Type.IsVariadic())
+	assert.Equal(t, []string{"ctx", "greeting", "people"}, greet.Params)
+	assert.Equal(t, []string{"n", "err"}, greet.Results)
+	require.Equal(t, 3, greet.Type.NumIn())
+	assert.Equal(t, "context.Context", greet.Type.In(0).String())
+	assert.Equal(t, reflect.Slice, greet.Type.In(2).Kind())
+	assert.Equal(t, pkg.Types["Person"], greet.Type.In(2).Elem())
+	assert.Equal(t, TypeOf((*error)(nil)).Elem(), greet.Type.Out(1))
+
+	max := pkg.Funcs["Max"]
+	require.NotNil(t, max)
+	require.Equal(t, 1, max.Type.NumTypeParam())
+	param := max.Type.TypeParam(0)
+	assert.Equal(t, "T", param.Name)
+	assert.Equal(t, pkg.Types["Number"], param.Constraint)
+	assert.Equal(t, param.Type, max.Type.Out(0))
+	assert.Equal(t, param.Type, max.Type.In(0).Elem())
+	assert.Equal(t, "", max.Doc)
+
+	lookup := pkg.Funcs["Lookup"]
+	require.NotNil(t, lookup)
+	assert.Equal(t, []string{"", ""}, lookup.Params)
+	assert.Equal(t, pkg.Types["Color"], lookup.Type.In(0).Key())
+}
+
+func TestLoadPackage_FuncErrors(t *testing.T) {
+	dir := filepath.Join("testdata", "badfuncs")
+	types, err := LoadDir(dir)
+	require.NoError(t, err)
+	assert.Contains(t, types, "T")
+
+	pkg, err := LoadPackage(dir)
+	require.NoError(t, err)
+	for _, name := range []string{"H", "H2", "Index", "Keys", "Bad"} {
+		assert.NotContains(t, pkg.Funcs, name)
+	}
+	require.Contains(t, pkg.Funcs, "New")
+	assert.Equal(t, pkg.Types["T"], pkg.Funcs["New"].Type.Out(0).Elem())
+
+	// the failed import is reported once, although two functions use it
+	var msgs []string
+	for _, e := range pkg.Errors {
+		msgs = append(msgs, fmt.Sprintf("%d:%d: %v", e.Pos.Line, e.Pos.Column, e.Category))
+	}
+	assert.Equal(t, []string{
+		"3:8: import failed",
+		"14:18: invalid declaration",
+		"14:34: unknown identifier",
+		"16:26: unknown identifier",
+		"20:16: invalid declaration",
+	}, msgs)
+
+	src := `package test
+
+import "example.com/missing"
+
+type T struct{}
+
+func H(x missing.Thing) {}
+`
+	_, err = LoadTypes(strings.NewReader(src))
+	assert.NoError(t, err)
+}
+
+func TestLoadTypes_SizeofConversion(t *testing.T) {
+	src := `package test
+
+import "unsafe"
+
+const (
+	size  = 64
+	count = (size - unsafe.Sizeof([]unsafe.Pointer{})) / unsafe.Sizeof(unsafe.Pointer(nil))
+)
+
+type T struct {
+	a [count]int
+	b [unsafe.Sizeof(int32(0))]byte
+}
+`
+	types, err := LoadTypes(strings.NewReader(src), WithArch("amd64"))
+	require.NoError(t, err)
+	assert.Equal(t, 5, types["T"].Field(0).Type.Len())
+	assert.Equal(t, 4, types["T"].Field(1).Type.Len())
+}
+
+func TestLoadTypes_ArrayLengthErrors(t *testing.T) {
+	src := `package test
+
+import "unsafe"
+
+const (
+	A       = 1 << 70
+	B uint8 = 254 + iota
+	C
+	D = E
+	E = D
+	F = 1.5
+	G = "s" + 1
+)
+
+type T struct {
+	a [A]int
+	b [B]int
+	c [C]int
+	d [D]int
+	e [F]int
+	f [-1]int
+	g [x]int
+	h [iota]int
+	i [1 / 0]int
+	j [int8(200)]int
+	k [G]int
+	l [unsafe.Sizeof(T{})]int
+}
+`
+	_, err := LoadTypes(strings.NewReader(src))
+	require.Error(t, err)
+
+	errs, ok := err.(ErrorList)
+	require.True(t, ok, err.Error())
+	var msgs []string
+	for _, e := range errs {
+		msgs = append(msgs, fmt.Sprintf("%d:%d: %v: %s", e.Pos.Line, e.Pos.Column, e.Category, e.Msg))
+	}
+	assert.Equal(t, []string{
+		"8:2: invalid constant: constant 256 overflows uint8",
+		"9:2: invalid constant: initialization cycle: D refers to itself",
+		"12:10: invalid constant: invalid operation: operator + not defined on \"s\" + 1",
+		"16:5: invalid array length: invalid array length A (constant 1180591620717411303424)",
+		"20:5: invalid array length: array length F (constant 1.5) must be integer",
+		"21:5: invalid array length: invalid array length -1 (constant -1)",
+		"22:5: invalid array length: undefined constant: x",
+		"23:5: invalid array length: cannot use iota outside constant declaration",
+		"24:9: invalid array length: invalid operation: division by zero",
+		"25:5: invalid array length: constant 200 overflows int8",
+		"27:19: invalid array length: invalid recursive type test.T",
+	}, msgs)
+}
+
+func TestLoadTypes_ArrayTooLarge(t *testing.T) {
+	src := `package test
+
+type A [1 << 62]int64
+
+type T struct {
+	b [1 << 29]int64
+	c [1 << 62]struct{}
+}
+`
+	_, err := LoadTypes(strings.NewReader(src), WithArch("amd64"))
+	require.Error(t, err)
+	errs, ok := err.(ErrorList)
+	require.True(t, ok)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "array too large", errs[0].Msg)
+	assert.Equal(t, InvalidArrayLength, errs[0].Category)
+	assert.Equal(t, 3, errs[0].Pos.Line)
+
+	// the largest size depends on the architecture
+	src = `package test
+
+type T struct {
+	b [1 << 29]int64
+	c [1 << 28]int32
+}
+`
+	_, err = LoadTypes(strings.NewReader(src), WithArch("386"))
+	require.Error(t, err)
+	errs, ok = err.(ErrorList)
+	require.True(t, ok)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "array too large", errs[0].Msg)
+	assert.Equal(t, 4, errs[0].Pos.Line)
+}
+
+func TestLoadTypes_ValidationErrors(t *testing.T) {
+	src := `package test
+
+type Bytes map[[]byte]int
+
+type Person struct {
+	Name     string
+	Children []*Person
+}
+
+type Index map[Person]int
+
+type T struct {
+	t T
+}
+
+type A struct {
+	b [2]B
+}
+
+type B struct {
+	a A
+}
+
+type Dup struct {
+	Name string
+	Person
+	Name int
+	*Person
+}
+
+func (d Dup) String() string { return "" }
+
+func (d *Dup) String() string { return "" }
+
+func (d Dup) Name() string { return "" }
+
+type Set[K any] map[K]bool
+
+type Handler func(map[func()]bool) error
+
+type Blank _
+
+func (_) Method() {}
+
+type _ struct{}
+
+type _ int
+`
+	_, err := LoadTypes(strings.NewReader(src))
+	require.Error(t, err)
+
+	errs, ok := err.(ErrorList)
+	require.True(t, ok)
+	var msgs []string
+	for _, e := range errs {
+		assert.Equal(t, InvalidDeclaration, e.Category)
+		msgs = append(msgs, fmt.Sprintf("%d:%d: %s", e.Pos.Line, e.Pos.Column, e.Msg))
+	}
+	assert.Equal(t, []string{
+		"3:16: invalid map key type []uint8",
+		"10:16: invalid map key type test.Person (test.Person.Children is a slice)",
+		"12:6: invalid recursive type T (T refers to T)",
+		"16:6: invalid recursive type A (A refers to B refers to A)",
+		"27:2: duplicate field Name",
+		"28:2: duplicate field Person",
+		"33:15: method Dup.String already declared",
+		"35:14: field and method with the same name Name",
+		"37:21: invalid map key type K",
+		"39:23: invalid map key type func()",
+		"41:12: cannot use _ as a type",
+		"43:7: cannot use _ as a type",
+	}, msgs)
+}
+
+func TestLoadTypes_ConstraintAsType(t *testing.T) {
+	src := `package test
+
+type I interface{ ~int }
+
+type C interface{ comparable }
+
+type T struct {
+	x I
+	y []C
+	z func(comparable)
+}
+
+type J I
+
+type Ok[P I] struct {
+	p P
+}
+`
+	_, err := LoadTypes(strings.NewReader(src))
+	require.Error(t, err)
+
+	errs, ok := err.(ErrorList)
+	require.True(t, ok)
+	var msgs []string
+	for _, e := range errs {
+		msgs = append(msgs, fmt.Sprintf("%d:%d: %v: %s", e.Pos.Line, e.Pos.Column, e.Category, e.Msg))
+	}
+	assert.Equal(t, []string{
+		"8:4: invalid declaration: cannot use type test.I outside a type constraint: interface contains type constraints",
+		"9:6: invalid declaration: cannot use type test.C outside a type constraint: interface is (or embeds) comparable",
+		"10:9: invalid declaration: cannot use type comparable outside a type constraint: interface is (or embeds) comparable",
+	}, msgs)
+}
+
+func TestLoadTypes_InstantiateErrors(t *testing.T) {
+	src := `package test
+
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+type Plain struct{}
+
+type T struct {
+	A Pair[int]
+	B Plain[int]
+}
+
+type Chain[T any] struct {
+	Next *Chain[[]T]
+}
+
+type U struct {
+	C Chain[int]
+}
+`
+	_, err := LoadTypes(strings.NewReader(src))
+	require.Error(t, err)
+
+	errs, ok := err.(ErrorList)
+	require.True(t, ok)
+	require.Len(t, errs, 3)
+	assert.Contains(t, errs[0].Msg, "wrong number of type arguments")
+	assert.Contains(t, errs[1].Msg, "not a generic type")
+	assert.Contains(t, errs[2].Msg, "instantiation cycle")
+}
+
+func TestLoadTypes_GenericOwnArgs(t *testing.T) {
+	src := `package test
+
+import "sync/atomic"
+
+type Box[T any] struct {
+	v *T
+}
+
+type Node[T any] struct {
+	Value T
+	Kids  []Box[Node[T]]
+	Next  atomic.Pointer[Node[T]]
+}
+
+type Tree struct {
+	Root Node[string]
+}
+`
+	types, err := LoadTypes(strings.NewReader(src))
+	require.NoError(t, err)
+
+	root := types["Tree"].Field(0).Type
+	kids := root.Field(1).Type
+	assert.Equal(t, reflect.Slice, kids.Kind())
+	assert.Equal(t, root, kids.Elem().Field(0).Type.Elem())
+}
+
+func TestLoadTypes_TypeParamDeclaration(t *testing.T) {
+	src := `package test
+
+type T[P any] P
+
+type U[P any] (P)
+`
+	_, err := LoadTypes(strings.NewReader(src))
+	require.Error(t, err)
+
+	errs, ok := err.(ErrorList)
+	require.True(t, ok)
+	require.Len(t, errs, 2)
+	assert.Equal(t, "cannot use a type parameter as RHS in type declaration", errs[0].Msg)
+	assert.Equal(t, 3, errs[0].Pos.Line)
+	assert.Equal(t, 15, errs[0].Pos.Column)
+	assert.Equal(t, 5, errs[1].Pos.Line)
+}
+
+func TestLoadTypes_GenericWithoutInstantiation(t *testing.T) {
+	src := `package test
+
+type Page[T any] struct {
+	Items []T
+}
+
+type T struct {
+	X Page
+	Y *Page[int]
+}
+
+type A = Page
+`
+	_, err := LoadTypes(strings.NewReader(src))
+	require.Error(t, err)
+
+	errs, ok := err.(ErrorList)
+	require.True(t, ok)
+	require.Len(t, errs, 2)
+	assert.Equal(t, "cannot use generic type test.Page without instantiation", errs[0].Msg)
+	assert.Equal(t, 8, errs[0].Pos.Line)
+	assert.Equal(t, 4, errs[0].Pos.Column)
+	assert.Equal(t, InvalidDeclaration, errs[0].Category)
+	assert.Equal(t, 12, errs[1].Pos.Line)
+}
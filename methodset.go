@@ -0,0 +1,114 @@
+package mold
+
+import (
+	"reflect"
+	"sort"
+)
+
+// methodScan is a type to be searched for methods, together with whether
+// it was reached through a pointer, which makes methods with pointer
+// receivers available
+type methodScan struct {
+	typ      Type
+	indirect bool
+}
+
+// promotedMethods returns the method set of T, or of *T if indirect is
+// true, for a type T that is not an interface. The method set includes
+// methods promoted from embedded fields: a method at a shallower depth
+// shadows methods and fields with the same name at greater depths, and a
+// name that appears more than once at the shallowest depth at which it
+// appears is ambiguous, so it is not in the method set. The result
+// includes unexported methods and is sorted by name.
+func promotedMethods(t Type, indirect bool) []*method {
+	var methods []*method
+	current := []methodScan{{typ: t, indirect: indirect}}
+	searched := make(map[Type]bool)
+	decided := make(map[string]bool) // names found or shadowed at a shallower depth
+
+	for len(current) > 0 {
+		var next []methodScan
+
+		// candidates for each name at this depth, with nil standing for a
+		// field or a method that cannot be called through this path
+		candidates := make(map[string][]*method)
+		for _, scan := range current {
+			if searched[scan.typ] {
+				continue
+			}
+			if scan.typ.Kind() == reflect.Interface {
+				for _, m := range interfaceMethods(scan.typ) {
+					candidates[m.name] = append(candidates[m.name], m)
+				}
+				continue
+			}
+			for _, m := range declaredMethods(scan.typ) {
+				if m.ptrRecv && !scan.indirect {
+					candidates[m.name] = append(candidates[m.name], nil)
+				} else {
+					candidates[m.name] = append(candidates[m.name], m)
+				}
+			}
+
+			// the method sets of live types already include promoted methods
+			if _, live := scan.typ.(liveType); live || scan.typ.Kind() != reflect.Struct {
+				continue
+			}
+			for i := 0; i < scan.typ.NumField(); i++ {
+				f := scan.typ.Field(i)
+				candidates[f.Name] = append(candidates[f.Name], nil)
+				if !f.Anonymous {
+					continue
+				}
+				embedded := methodScan{typ: f.Type, indirect: scan.indirect}
+				if f.Type.Kind() == reflect.Ptr {
+					embedded = methodScan{typ: f.Type.Elem(), indirect: true}
+				}
+				next = append(next, embedded)
+			}
+		}
+		for _, scan := range current {
+			searched[scan.typ] = true
+		}
+
+		for name, ms := range candidates {
+			if decided[name] {
+				continue
+			}
+			decided[name] = true
+			if len(ms) == 1 && ms[0] != nil {
+				methods = append(methods, ms[0])
+			}
+		}
+		current = next
+	}
+
+	sort.Slice(methods, func(i, j int) bool {
+		return methods[i].name < methods[j].name
+	})
+	return methods
+}
+
+// declaredMethods returns the methods declared with receiver type T or *T
+// for a type T that is not an interface
+func declaredMethods(t Type) []*method {
+	switch t := t.(type) {
+	case liveType:
+		methods := liveMethods(reflect.PointerTo(t.Type))
+		for _, m := range methods {
+			if _, found := t.Type.MethodByName(m.name); !found {
+				m.ptrRecv = true
+			}
+		}
+		return methods
+	case interface {
+		Type
+		base() *staticType
+	}:
+		if k := t.Kind(); k == reflect.Ptr || k == reflect.Interface {
+			return nil
+		}
+		return t.base().methods
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package mold
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// An Option configures how types are loaded
+type Option func(*loader)
+
+// WithPkgPath sets the import path of the package being loaded, which
+// is reported by PkgPath for the types declared in it. By default the
+// import path is determined from the enclosing go.mod file, or from
+// GOPATH if there is no go.mod file.
+func WithPkgPath(path string) Option {
+	return func(l *loader) {
+		l.pkgPath = path
+	}
+}
+
+// WithArch sets the target architecture, such as "amd64", "386", "arm" or
+// "wasm", which determines the sizes and alignments of types as well as
+// which files are loaded according to their build constraints. By default
+// the target is the host architecture, or $GOARCH if it is set. Predeclared
+// types such as int are represented by reflect, so their Size and Align
+// always describe the host, but they are laid out for the target when they
+// appear within loaded types.
+func WithArch(goarch string) Option {
+	return func(l *loader) {
+		l.ctxt.GOARCH = goarch
+	}
+}
+
+// importPath determines the import path of the package in dir. If the
+// import path cannot be determined then the package name is used.
+func (l *loader) importPath(dir, name string) string {
+	if l.pkgPath != "" {
+		return l.pkgPath
+	}
+	if path, ok := moduleImportPath(dir); ok {
+		return path
+	}
+	for _, src := range l.ctxt.SrcDirs() {
+		if rel, err := filepath.Rel(src, dir); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return name
+}
+
+// moduleImportPath finds the go.mod file enclosing dir and joins its
+// module path with the location of dir within the module
+func moduleImportPath(dir string) (string, bool) {
+	for root := dir; ; {
+		if data, err := os.ReadFile(filepath.Join(root, "go.mod")); err == nil {
+			module := modulePath(data)
+			if module == "" {
+				return "", false
+			}
+			rel, err := filepath.Rel(root, dir)
+			if err != nil {
+				return "", false
+			}
+			if rel == "." {
+				return module, true
+			}
+			return module + "/" + filepath.ToSlash(rel), true
+		}
+		parent := filepath.Dir(root)
+		if parent == root {
+			return "", false
+		}
+		root = parent
+	}
+}
+
+// modulePath returns the path in the module directive of a go.mod file,
+// or the empty string if there is none
+func modulePath(data []byte) string {
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		rest := strings.TrimPrefix(line, "module")
+		if rest == line || rest == "" || (rest[0] != ' ' && rest[0] != '\t' && rest[0] != '"') {
+			continue // some other word beginning with "module"
+		}
+		path := strings.TrimSpace(rest)
+		if unquoted, err := strconv.Unquote(path); err == nil {
+			path = unquoted
+		}
+		return path
+	}
+	return ""
+}
@@ -0,0 +1,197 @@
+package mold
+
+import (
+	"reflect"
+	"runtime"
+)
+
+// sizes computes the memory layout of types on a target architecture,
+// following the rules of the gc compiler so that the results agree with
+// reflect when the target is the host
+type sizes struct {
+	wordSize int64 // size of a pointer, in bytes
+	maxAlign int64 // maximum alignment of any type, in bytes
+}
+
+// archSizes holds the word size and maximum alignment for each
+// architecture supported by the gc compiler, as in go/types
+var archSizes = map[string]sizes{
+	"386":      {4, 4},
+	"amd64":    {8, 8},
+	"amd64p32": {4, 8},
+	"arm":      {4, 4},
+	"arm64":    {8, 8},
+	"loong64":  {8, 8},
+	"mips":     {4, 4},
+	"mipsle":   {4, 4},
+	"mips64":   {8, 8},
+	"mips64le": {8, 8},
+	"ppc64":    {8, 8},
+	"ppc64le":  {8, 8},
+	"riscv64":  {8, 8},
+	"s390x":    {8, 8},
+	"sparc64":  {8, 8},
+	"wasm":     {8, 8},
+}
+
+// hostSizes is used for types that were not loaded for a particular
+// architecture
+var hostSizes = sizesFor(runtime.GOARCH)
+
+// sizesFor returns the layout rules for an architecture, or nil if the
+// architecture is not known
+func sizesFor(arch string) *sizes {
+	s, found := archSizes[arch]
+	if !found {
+		return nil
+	}
+	return &s
+}
+
+// alignof returns the alignment of a variable of type t
+func (s *sizes) alignof(t Type) int64 {
+	switch t.Kind() {
+	case reflect.Array:
+		return s.alignof(t.Elem())
+	case reflect.Struct:
+		if st, ok := t.(*staticStruct); ok {
+			return st.computeLayout(s).align
+		}
+		align := int64(1)
+		for i := 0; i < t.NumField(); i++ {
+			if a := s.alignof(t.Field(i).Type); a > align {
+				align = a
+			}
+		}
+		return align
+	case reflect.Complex64, reflect.Complex128:
+		// complex numbers are aligned like their components
+		return s.basicAlign(s.sizeof(t) / 2)
+	case reflect.String, reflect.Slice, reflect.Interface:
+		return s.wordSize
+	}
+	return s.basicAlign(s.sizeof(t))
+}
+
+func (s *sizes) basicAlign(size int64) int64 {
+	if size < 1 {
+		return 1
+	}
+	if size > s.maxAlign {
+		return s.maxAlign
+	}
+	return size
+}
+
+// maxInt returns the largest value of type int
+func (s *sizes) maxInt() int64 {
+	return 1<<(8*s.wordSize-1) - 1
+}
+
+// sizeof returns the size of a variable of type t
+func (s *sizes) sizeof(t Type) int64 {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int8, reflect.Uint8:
+		return 1
+	case reflect.Int16, reflect.Uint16:
+		return 2
+	case reflect.Int32, reflect.Uint32, reflect.Float32:
+		return 4
+	case reflect.Int64, reflect.Uint64, reflect.Float64, reflect.Complex64:
+		return 8
+	case reflect.Complex128:
+		return 16
+	case reflect.Int, reflect.Uint, reflect.Uintptr, reflect.UnsafePointer,
+		reflect.Ptr, reflect.Map, reflect.Chan, reflect.Func:
+		return s.wordSize
+	case reflect.String:
+		return 2 * s.wordSize
+	case reflect.Slice:
+		return 3 * s.wordSize
+	case reflect.Interface:
+		if _, ok := t.(*staticTypeParam); ok {
+			return 0 // the size of a type parameter is not known
+		}
+		return 2 * s.wordSize
+	case reflect.Array:
+		return int64(t.Len()) * s.sizeof(t.Elem())
+	case reflect.Struct:
+		if st, ok := t.(*staticStruct); ok {
+			return st.computeLayout(s).size
+		}
+		var fields []Type
+		for i := 0; i < t.NumField(); i++ {
+			fields = append(fields, t.Field(i).Type)
+		}
+		size, _, _ := s.layoutFields(fields)
+		return size
+	}
+	return 0 // invalid type, which has already been reported
+}
+
+// layoutFields computes the offset of each field in a struct with the
+// given field types, together with the size and alignment of the struct
+func (s *sizes) layoutFields(fields []Type) (size, align int64, offsets []int64) {
+	align = 1
+	for _, f := range fields {
+		a, n := s.alignof(f), s.sizeof(f)
+		size = alignUp(size, a)
+		offsets = append(offsets, size)
+		size += n
+		if a > align {
+			align = a
+		}
+	}
+	// a struct that ends in a zero-sized field is padded so that taking the
+	// address of the final field does not point past the end of the struct
+	if n := len(fields); n > 0 && size > 0 && s.sizeof(fields[n-1]) == 0 {
+		size++
+	}
+	return alignUp(size, align), align, offsets
+}
+
+// alignUp rounds x up to a multiple of align
+func alignUp(x, align int64) int64 {
+	return (x + align - 1) / align * align
+}
+
+// structLayout is the layout of a static struct, which is computed once
+// all types have been populated since the fields may refer to types
+// declared later
+type structLayout struct {
+	size  int64
+	align int64
+	state layoutState
+}
+
+type layoutState int
+
+const (
+	layoutPending layoutState = iota
+	layoutComputing
+	layoutComputed
+)
+
+// computeLayout computes the offsets of the fields of a struct, together
+// with its size and alignment. An invalid struct that contains itself is
+// laid out as if the recursive field had size zero.
+func (t *staticStruct) computeLayout(s *sizes) *structLayout {
+	if t.layout.state != layoutPending {
+		return t.layout
+	}
+	t.layout.state = layoutComputing
+	// a field that refers back to this struct sees it with size zero, and
+	// alignment one so that the offsets of later fields can be aligned
+	t.layout.align = 1
+	var types []Type
+	for _, f := range t.fields {
+		types = append(types, f.Type)
+	}
+	size, align, offsets := s.layoutFields(types)
+	for i := range t.fields {
+		t.fields[i].Offset = uintptr(offsets[i])
+	}
+	t.layout.size, t.layout.align = size, align
+	t.layout.state = layoutComputed
+	return t.layout
+}
@@ -3,20 +3,68 @@ package mold
 import (
 	"fmt"
 	"go/ast"
+	"go/token"
 	"reflect"
+	"strconv"
+	"strings"
 )
 
-// -- staticAlias
+// -- staticDefined
 
-type staticAlias struct {
+type staticDefined struct {
 	Type
 	st   staticType
-	expr *ast.Ident
+	expr ast.Expr
 }
 
-func (t *staticAlias) Name() string    { return t.st.name }
-func (t *staticAlias) PkgPath() string { return t.st.PkgPath() }
-func (t *staticAlias) String() string  { return t.PkgPath() }
+func (t *staticDefined) Name() string              { return t.st.name }
+func (t *staticDefined) PkgPath() string           { return t.st.PkgPath() }
+func (t *staticDefined) String() string            { return t.st.qualifiedName() }
+func (t *staticDefined) NumTypeParam() int         { return t.st.NumTypeParam() }
+func (t *staticDefined) TypeParam(i int) TypeParam { return t.st.TypeParam(i) }
+func (t *staticDefined) base() *staticType         { return &t.st }
+
+// The underlying type may be a live type such as int, whose layout is that
+// of the host, so the layout must follow the architecture of t itself.
+func (t *staticDefined) Size() uintptr   { return uintptr(t.st.sizes().sizeof(t)) }
+func (t *staticDefined) Align() int      { return int(t.st.sizes().alignof(t)) }
+func (t *staticDefined) FieldAlign() int { return int(t.st.sizes().alignof(t)) }
+
+// The embedded type is the underlying type, which lacks the methods and
+// name of the defined type, so relations between types must use t itself.
+func (t *staticDefined) Implements(u Type) bool    { return implementsType(t, u) }
+func (t *staticDefined) AssignableTo(u Type) bool  { return assignableTo(t, u) }
+func (t *staticDefined) ConvertibleTo(u Type) bool { return convertibleTo(t, u) }
+
+// methodSet returns the exported methods of a defined type, which are those
+// declared on it and, for a struct, those promoted from its embedded fields
+func (t *staticDefined) methodSet() []*method {
+	return exportedMethods(promotedMethods(t, false), true)
+}
+
+func (t *staticDefined) NumMethod() int {
+	if t.Kind() == reflect.Interface {
+		return t.Type.NumMethod()
+	}
+	return len(t.methodSet())
+}
+
+func (t *staticDefined) Method(i int) Method {
+	if t.Kind() == reflect.Interface {
+		return t.Type.Method(i)
+	}
+	return t.methodSet()[i].export(i, t)
+}
+
+func (t *staticDefined) MethodByName(name string) (Method, bool) {
+	if t.Kind() == reflect.Interface {
+		return t.Type.MethodByName(name)
+	}
+	if i, m := findMethod(t.methodSet(), name); m != nil {
+		return m.export(i, t), true
+	}
+	return Method{}, false
+}
 
 // -- staticPtr
 
@@ -26,9 +74,60 @@ type staticPtr struct {
 	expr *ast.StarExpr
 }
 
-func (t *staticPtr) Kind() reflect.Kind { return reflect.Ptr }
-func (t *staticPtr) Elem() Type         { return t.elem }
-func (t *staticPtr) String() string     { return "*" + t.elem.String() }
+func (t *staticPtr) Kind() reflect.Kind        { return reflect.Ptr }
+func (t *staticPtr) Underlying() Type          { return underlying(t) }
+func (t *staticPtr) Size() uintptr             { return uintptr(t.sizes().sizeof(t)) }
+func (t *staticPtr) Align() int                { return int(t.sizes().alignof(t)) }
+func (t *staticPtr) FieldAlign() int           { return int(t.sizes().alignof(t)) }
+func (t *staticPtr) Implements(u Type) bool    { return implementsType(t, u) }
+func (t *staticPtr) AssignableTo(u Type) bool  { return assignableTo(t, u) }
+func (t *staticPtr) ConvertibleTo(u Type) bool { return convertibleTo(t, u) }
+func (t *staticPtr) Comparable() bool          { return isComparable(t) }
+func (t *staticPtr) Elem() Type                { return t.elem }
+func (t *staticPtr) String() string {
+	if t.name != "" {
+		return t.qualifiedName()
+	}
+	return "*" + t.elem.String()
+}
+
+// methodSet returns the exported methods of *T, which include the methods
+// declared with receiver type T as well as those with receiver type *T,
+// together with methods promoted from the embedded fields of T. A named
+// pointer type has no methods.
+func (t *staticPtr) methodSet() []*method {
+	if t.name != "" {
+		return nil
+	}
+	switch elem := t.elem.(type) {
+	case liveType:
+		return liveMethods(reflect.PointerTo(elem.Type))
+	case interface {
+		Type
+		base() *staticType
+	}:
+		if k := elem.Kind(); k == reflect.Interface || k == reflect.Ptr {
+			return nil
+		}
+		return exportedMethods(promotedMethods(elem, true), true)
+	}
+	return nil
+}
+
+func (t *staticPtr) NumMethod() int {
+	return len(t.methodSet())
+}
+
+func (t *staticPtr) Method(i int) Method {
+	return t.methodSet()[i].export(i, t)
+}
+
+func (t *staticPtr) MethodByName(name string) (Method, bool) {
+	if i, m := findMethod(t.methodSet(), name); m != nil {
+		return m.export(i, t), true
+	}
+	return Method{}, false
+}
 
 // -- staticArray
 
@@ -39,10 +138,21 @@ type staticArray struct {
 	expr   *ast.ArrayType
 }
 
-func (t *staticArray) Kind() reflect.Kind { return reflect.Array }
-func (t *staticArray) Elem() Type         { return t.elem }
-func (t *staticArray) Len() int           { return t.length }
+func (t *staticArray) Kind() reflect.Kind        { return reflect.Array }
+func (t *staticArray) Underlying() Type          { return underlying(t) }
+func (t *staticArray) Size() uintptr             { return uintptr(t.sizes().sizeof(t)) }
+func (t *staticArray) Align() int                { return int(t.sizes().alignof(t)) }
+func (t *staticArray) FieldAlign() int           { return int(t.sizes().alignof(t)) }
+func (t *staticArray) Implements(u Type) bool    { return implementsType(t, u) }
+func (t *staticArray) AssignableTo(u Type) bool  { return assignableTo(t, u) }
+func (t *staticArray) ConvertibleTo(u Type) bool { return convertibleTo(t, u) }
+func (t *staticArray) Comparable() bool          { return isComparable(t) }
+func (t *staticArray) Elem() Type                { return t.elem }
+func (t *staticArray) Len() int                  { return t.length }
 func (t *staticArray) String() string {
+	if t.name != "" {
+		return t.qualifiedName()
+	}
 	return fmt.Sprintf("[%d]%s", t.length, t.elem.String())
 }
 
@@ -54,9 +164,22 @@ type staticSlice struct {
 	expr *ast.ArrayType
 }
 
-func (t *staticSlice) Kind() reflect.Kind { return reflect.Slice }
-func (t *staticSlice) Elem() Type         { return t.elem }
-func (t *staticSlice) String() string     { return "[]" + t.elem.String() }
+func (t *staticSlice) Kind() reflect.Kind        { return reflect.Slice }
+func (t *staticSlice) Underlying() Type          { return underlying(t) }
+func (t *staticSlice) Size() uintptr             { return uintptr(t.sizes().sizeof(t)) }
+func (t *staticSlice) Align() int                { return int(t.sizes().alignof(t)) }
+func (t *staticSlice) FieldAlign() int           { return int(t.sizes().alignof(t)) }
+func (t *staticSlice) Implements(u Type) bool    { return implementsType(t, u) }
+func (t *staticSlice) AssignableTo(u Type) bool  { return assignableTo(t, u) }
+func (t *staticSlice) ConvertibleTo(u Type) bool { return convertibleTo(t, u) }
+func (t *staticSlice) Comparable() bool          { return isComparable(t) }
+func (t *staticSlice) Elem() Type                { return t.elem }
+func (t *staticSlice) String() string {
+	if t.name != "" {
+		return t.qualifiedName()
+	}
+	return "[]" + t.elem.String()
+}
 
 // -- staticMap
 
@@ -67,45 +190,481 @@ type staticMap struct {
 	expr *ast.MapType
 }
 
-func (t *staticMap) Kind() reflect.Kind { return reflect.Map }
-func (t *staticMap) Key() Type          { return t.key }
-func (t *staticMap) Elem() Type         { return t.elem }
+func (t *staticMap) Kind() reflect.Kind        { return reflect.Map }
+func (t *staticMap) Underlying() Type          { return underlying(t) }
+func (t *staticMap) Size() uintptr             { return uintptr(t.sizes().sizeof(t)) }
+func (t *staticMap) Align() int                { return int(t.sizes().alignof(t)) }
+func (t *staticMap) FieldAlign() int           { return int(t.sizes().alignof(t)) }
+func (t *staticMap) Implements(u Type) bool    { return implementsType(t, u) }
+func (t *staticMap) AssignableTo(u Type) bool  { return assignableTo(t, u) }
+func (t *staticMap) ConvertibleTo(u Type) bool { return convertibleTo(t, u) }
+func (t *staticMap) Comparable() bool          { return isComparable(t) }
+func (t *staticMap) Key() Type                 { return t.key }
+func (t *staticMap) Elem() Type                { return t.elem }
 func (t *staticMap) String() string {
+	if t.name != "" {
+		return t.qualifiedName()
+	}
 	return fmt.Sprintf("map[%s]%s", t.key.String(), t.elem.String())
 }
 
+// -- staticChan
+
+type staticChan struct {
+	staticType
+	dir  reflect.ChanDir
+	elem Type
+	expr *ast.ChanType
+}
+
+func (t *staticChan) Kind() reflect.Kind        { return reflect.Chan }
+func (t *staticChan) Underlying() Type          { return underlying(t) }
+func (t *staticChan) Size() uintptr             { return uintptr(t.sizes().sizeof(t)) }
+func (t *staticChan) Align() int                { return int(t.sizes().alignof(t)) }
+func (t *staticChan) FieldAlign() int           { return int(t.sizes().alignof(t)) }
+func (t *staticChan) Implements(u Type) bool    { return implementsType(t, u) }
+func (t *staticChan) AssignableTo(u Type) bool  { return assignableTo(t, u) }
+func (t *staticChan) ConvertibleTo(u Type) bool { return convertibleTo(t, u) }
+func (t *staticChan) Comparable() bool          { return isComparable(t) }
+func (t *staticChan) ChanDir() reflect.ChanDir  { return t.dir }
+func (t *staticChan) Elem() Type                { return t.elem }
+func (t *staticChan) String() string {
+	if t.name != "" {
+		return t.qualifiedName()
+	}
+	switch t.dir {
+	case reflect.SendDir:
+		return "chan<- " + t.elem.String()
+	case reflect.RecvDir:
+		return "<-chan " + t.elem.String()
+	}
+	// "chan <-chan T" would parse as "chan<- chan T", but a named element
+	// type needs no parentheses
+	if t.elem.Name() == "" && t.elem.Kind() == reflect.Chan && t.elem.ChanDir() == reflect.RecvDir {
+		return "chan (" + t.elem.String() + ")"
+	}
+	return "chan " + t.elem.String()
+}
+
+// -- staticFunc
+
+type staticFunc struct {
+	staticType
+	in       []Type
+	out      []Type
+	variadic bool
+	expr     *ast.FuncType
+}
+
+func (t *staticFunc) Kind() reflect.Kind        { return reflect.Func }
+func (t *staticFunc) Underlying() Type          { return underlying(t) }
+func (t *staticFunc) Size() uintptr             { return uintptr(t.sizes().sizeof(t)) }
+func (t *staticFunc) Align() int                { return int(t.sizes().alignof(t)) }
+func (t *staticFunc) FieldAlign() int           { return int(t.sizes().alignof(t)) }
+func (t *staticFunc) Implements(u Type) bool    { return implementsType(t, u) }
+func (t *staticFunc) AssignableTo(u Type) bool  { return assignableTo(t, u) }
+func (t *staticFunc) ConvertibleTo(u Type) bool { return convertibleTo(t, u) }
+func (t *staticFunc) Comparable() bool          { return isComparable(t) }
+func (t *staticFunc) NumIn() int                { return len(t.in) }
+func (t *staticFunc) In(i int) Type             { return t.in[i] }
+func (t *staticFunc) NumOut() int               { return len(t.out) }
+func (t *staticFunc) Out(i int) Type            { return t.out[i] }
+func (t *staticFunc) IsVariadic() bool          { return t.variadic }
+func (t *staticFunc) String() string {
+	if t.name != "" {
+		return t.qualifiedName()
+	}
+	return "func" + t.signature()
+}
+
+// signature formats the parameters and results as in a function declaration
+func (t *staticFunc) signature() string {
+	var in []string
+	for i, p := range t.in {
+		if t.variadic && i == len(t.in)-1 {
+			in = append(in, "..."+p.Elem().String())
+		} else {
+			in = append(in, p.String())
+		}
+	}
+	s := "(" + strings.Join(in, ", ") + ")"
+
+	var out []string
+	for _, p := range t.out {
+		out = append(out, p.String())
+	}
+	switch len(out) {
+	case 0:
+		return s
+	case 1:
+		return s + " " + out[0]
+	default:
+		return s + " (" + strings.Join(out, ", ") + ")"
+	}
+}
+
 // -- staticStruct
 
 type staticStruct struct {
 	staticType
 	fields []StructField
+	layout *structLayout // shared with the underlying type of a named struct
 	expr   *ast.StructType
 }
 
-func (t *staticStruct) Kind() reflect.Kind      { return reflect.Struct }
-func (t *staticStruct) NumField() int           { return len(t.fields) }
-func (t *staticStruct) Field(i int) StructField { return t.fields[i] }
-func (t *staticStruct) String() string          { return t.PkgPath() }
+func (t *staticStruct) Kind() reflect.Kind        { return reflect.Struct }
+func (t *staticStruct) Underlying() Type          { return underlying(t) }
+func (t *staticStruct) Size() uintptr             { return uintptr(t.sizes().sizeof(t)) }
+func (t *staticStruct) Align() int                { return int(t.sizes().alignof(t)) }
+func (t *staticStruct) FieldAlign() int           { return int(t.sizes().alignof(t)) }
+func (t *staticStruct) Implements(u Type) bool    { return implementsType(t, u) }
+func (t *staticStruct) AssignableTo(u Type) bool  { return assignableTo(t, u) }
+func (t *staticStruct) ConvertibleTo(u Type) bool { return convertibleTo(t, u) }
+func (t *staticStruct) Comparable() bool          { return isComparable(t) }
+func (t *staticStruct) NumField() int             { return len(t.fields) }
+func (t *staticStruct) Field(i int) StructField   { return t.fields[i] }
+
+// methodSet returns the exported methods of a struct, which are those
+// declared on it together with those promoted from its embedded fields
+func (t *staticStruct) methodSet() []*method {
+	return exportedMethods(promotedMethods(t, false), true)
+}
+
+func (t *staticStruct) NumMethod() int {
+	return len(t.methodSet())
+}
+
+func (t *staticStruct) Method(i int) Method {
+	return t.methodSet()[i].export(i, t)
+}
+
+func (t *staticStruct) MethodByName(name string) (Method, bool) {
+	if i, m := findMethod(t.methodSet(), name); m != nil {
+		return m.export(i, t), true
+	}
+	return Method{}, false
+}
+
+func (t *staticStruct) FieldByIndex(index []int) StructField {
+	return fieldByIndex(t, index)
+}
+
+func (t *staticStruct) FieldByName(name string) (StructField, bool) {
+	return fieldByNameFunc(t, func(s string) bool { return s == name })
+}
+
+func (t *staticStruct) FieldByNameFunc(match func(string) bool) (StructField, bool) {
+	return fieldByNameFunc(t, match)
+}
+
+func (t *staticStruct) String() string {
+	if t.name != "" {
+		return t.qualifiedName()
+	}
+	if len(t.fields) == 0 {
+		return "struct {}"
+	}
+	var fields []string
+	for _, f := range t.fields {
+		s := f.Type.String()
+		if !f.Anonymous {
+			s = f.Name + " " + s
+		}
+		if f.Tag != "" {
+			s += " " + strconv.Quote(string(f.Tag))
+		}
+		fields = append(fields, s)
+	}
+	return "struct { " + strings.Join(fields, "; ") + " }"
+}
 
 // -- staticInterface
 
 type staticInterface struct {
 	staticType
-	// TODO: add fields for methods
-	expr *ast.InterfaceType
+	methods    []*method // sorted by name
+	terms      []Term    // type set, if restricted
+	restricted bool      // whether the type set is restricted to terms
+	comparable bool      // whether the type set is restricted to comparable types
+	elems      []string  // elements whose intersection is the type set, for String
+	expr       *ast.InterfaceType
+}
+
+func (t *staticInterface) Kind() reflect.Kind        { return reflect.Interface }
+func (t *staticInterface) Underlying() Type          { return underlying(t) }
+func (t *staticInterface) Size() uintptr             { return uintptr(t.sizes().sizeof(t)) }
+func (t *staticInterface) Align() int                { return int(t.sizes().alignof(t)) }
+func (t *staticInterface) FieldAlign() int           { return int(t.sizes().alignof(t)) }
+func (t *staticInterface) Implements(u Type) bool    { return implementsType(t, u) }
+func (t *staticInterface) AssignableTo(u Type) bool  { return assignableTo(t, u) }
+func (t *staticInterface) ConvertibleTo(u Type) bool { return convertibleTo(t, u) }
+func (t *staticInterface) Comparable() bool          { return isComparable(t) }
+func (t *staticInterface) NumMethod() int            { return len(t.methods) }
+func (t *staticInterface) NumTerm() int              { return len(t.terms) }
+func (t *staticInterface) Term(i int) Term           { return t.terms[i] }
+func (t *staticInterface) IsMethodSet() bool         { return !t.restricted && !t.comparable }
+func (t *staticInterface) IsEmptyTypeSet() bool      { return t.restricted && len(t.terms) == 0 }
+func (t *staticInterface) Satisfies(u Type) bool     { return satisfies(u, t) }
+
+func (t *staticInterface) String() string {
+	if t.name != "" {
+		return t.qualifiedName()
+	}
+	var elems []string
+	if t.comparable {
+		elems = append(elems, "comparable")
+	}
+	if t.IsEmptyTypeSet() {
+		// the intersection has no terms to render, so render the elements
+		elems = append(elems, t.elems...)
+	} else if t.restricted {
+		elems = append(elems, formatTerms(t.terms))
+	}
+	for _, m := range t.methods {
+		elems = append(elems, m.name+strings.TrimPrefix(m.signature().String(), "func"))
+	}
+	if len(elems) == 0 {
+		return "interface {}"
+	}
+	return "interface { " + strings.Join(elems, "; ") + " }"
+}
+
+// formatTerms renders the terms of a union, such as "~int | string"
+func formatTerms(terms []Term) string {
+	var strs []string
+	for _, term := range terms {
+		if term.Tilde {
+			strs = append(strs, "~"+term.Type.String())
+		} else {
+			strs = append(strs, term.Type.String())
+		}
+	}
+	return strings.Join(strs, " | ")
+}
+
+func (t *staticInterface) Method(i int) Method {
+	return t.methods[i].export(i, nil)
+}
+
+func (t *staticInterface) MethodByName(name string) (Method, bool) {
+	if i, m := findMethod(t.methods, name); m != nil {
+		return m.export(i, nil), true
+	}
+	return Method{}, false
+}
+
+// comparableType is the predeclared constraint satisfied by all
+// comparable types
+var comparableType Type = &staticInterface{
+	staticType: staticType{name: "comparable"},
+	comparable: true,
+}
+
+// -- staticTypeParam
+
+// staticTypeParam is a type parameter of a generic declaration, as it
+// appears within the declaration. Like in go/types, its underlying type is
+// its constraint, so its kind is Interface and its methods are those of the
+// constraint.
+type staticTypeParam struct {
+	staticType
+	index      int
+	constraint Type
+}
+
+func (t *staticTypeParam) Kind() reflect.Kind        { return reflect.Interface }
+func (t *staticTypeParam) Underlying() Type          { return underlying(t) }
+func (t *staticTypeParam) Size() uintptr             { return uintptr(t.sizes().sizeof(t)) }
+func (t *staticTypeParam) Align() int                { return int(t.sizes().alignof(t)) }
+func (t *staticTypeParam) FieldAlign() int           { return int(t.sizes().alignof(t)) }
+func (t *staticTypeParam) Implements(u Type) bool    { return implementsType(t, u) }
+func (t *staticTypeParam) AssignableTo(u Type) bool  { return assignableTo(t, u) }
+func (t *staticTypeParam) ConvertibleTo(u Type) bool { return convertibleTo(t, u) }
+func (t *staticTypeParam) Comparable() bool          { return isComparable(t) }
+func (t *staticTypeParam) PkgPath() string           { return "" }
+func (t *staticTypeParam) String() string            { return t.name }
+func (t *staticTypeParam) NumMethod() int            { return t.constraint.NumMethod() }
+func (t *staticTypeParam) Method(i int) Method {
+	return t.constraint.Method(i)
+}
+func (t *staticTypeParam) MethodByName(name string) (Method, bool) {
+	return t.constraint.MethodByName(name)
+}
+func (t *staticTypeParam) NumTerm() int          { return t.constraint.NumTerm() }
+func (t *staticTypeParam) Term(i int) Term       { return t.constraint.Term(i) }
+func (t *staticTypeParam) IsMethodSet() bool     { return t.constraint.IsMethodSet() }
+func (t *staticTypeParam) IsEmptyTypeSet() bool  { return t.constraint.IsEmptyTypeSet() }
+func (t *staticTypeParam) Satisfies(u Type) bool { return t.constraint.Satisfies(u) }
+
+// -- method
+
+// method is a method in the method set of a static type
+type method struct {
+	name    string
+	pkgPath string // package path qualifying an unexported name
+	typ     Type   // signature, without the receiver
+	recv    Type   // type on which the method was declared, nil for interface methods
+	ptrRecv bool   // whether the method was declared with a pointer receiver
+	pos     token.Position
+	resolve func() Type // resolves typ on first use, if not nil
+}
+
+// signature returns the method signature, without the receiver
+func (m *method) signature() Type {
+	if m.resolve != nil {
+		m.typ = m.resolve()
+		m.resolve = nil
+	}
+	return m.typ
+}
+
+// export converts the method to a Method at the given index in a method
+// set. If recv is not nil then it is added as the first input parameter of
+// the method type.
+func (m *method) export(index int, recv Type) Method {
+	typ := m.signature()
+	if recv != nil {
+		typ = withReceiver(recv, typ)
+	}
+	return Method{
+		Name:            m.name,
+		PkgPath:         m.pkgPath,
+		Type:            typ,
+		Index:           index,
+		PointerReceiver: m.ptrRecv,
+		Pos:             m.pos,
+	}
+}
+
+// findMethod finds a method by name within a method set, returning its
+// index, or nil if there is no such method
+func findMethod(methods []*method, name string) (int, *method) {
+	for i, m := range methods {
+		if m.name == name {
+			return i, m
+		}
+	}
+	return -1, nil
+}
+
+// withReceiver constructs a func type like sig but with recv as the
+// first input parameter
+func withReceiver(recv, sig Type) Type {
+	if sig.Kind() != reflect.Func {
+		return sig // invalid signature, which has already been reported
+	}
+	t := &staticFunc{
+		in:       []Type{recv},
+		variadic: sig.IsVariadic(),
+	}
+	if named, ok := recv.(interface{ base() *staticType }); ok {
+		t.pkg = named.base().pkg // for the layout rules of the package
+	}
+	for i := 0; i < sig.NumIn(); i++ {
+		t.in = append(t.in, sig.In(i))
+	}
+	for i := 0; i < sig.NumOut(); i++ {
+		t.out = append(t.out, sig.Out(i))
+	}
+	return t
+}
+
+// exportedMethods selects the exported methods from a list of declared
+// methods, which is what reflect exposes for non-interface types. Methods
+// with pointer receivers are included only if ptr is true.
+func exportedMethods(methods []*method, ptr bool) []*method {
+	var exported []*method
+	for _, m := range methods {
+		if m.pkgPath == "" && (ptr || !m.ptrRecv) {
+			exported = append(exported, m)
+		}
+	}
+	return exported
+}
+
+// liveMethods returns the method set of a live type
+func liveMethods(t reflect.Type) []*method {
+	var methods []*method
+	for i := 0; i < t.NumMethod(); i++ {
+		m := t.Method(i)
+		typ := m.Type
+		if t.Kind() != reflect.Interface {
+			typ = withoutReceiver(typ)
+		}
+		methods = append(methods, &method{
+			name:    m.Name,
+			pkgPath: m.PkgPath,
+			typ:     liveType{typ},
+		})
+	}
+	return methods
+}
+
+// withoutReceiver removes the first input parameter from a live func type
+func withoutReceiver(t reflect.Type) reflect.Type {
+	var in, out []reflect.Type
+	for i := 1; i < t.NumIn(); i++ {
+		in = append(in, t.In(i))
+	}
+	for i := 0; i < t.NumOut(); i++ {
+		out = append(out, t.Out(i))
+	}
+	return reflect.FuncOf(in, out, t.IsVariadic())
+}
+
+// interfaceMethods returns the method set of an interface type
+func interfaceMethods(t Type) []*method {
+	switch t := t.(type) {
+	case *staticInterface:
+		return t.methods
+	case *staticDefined:
+		return interfaceMethods(t.Type)
+	case *staticTypeParam:
+		return interfaceMethods(t.constraint)
+	case liveType:
+		return liveMethods(t.Type)
+	}
+	return nil
 }
 
-func (t *staticInterface) Kind() reflect.Kind { return reflect.Interface }
-func (t *staticInterface) String() string     { return t.PkgPath() }
+// -- staticInvalid
+
+// staticInvalid stands in for a type that could not be constructed
+type staticInvalid struct {
+	staticType
+}
+
+// invalidType is returned when a type expression cannot be resolved
+var invalidType Type = &staticInvalid{}
+
+func (t *staticInvalid) Kind() reflect.Kind        { return reflect.Invalid }
+func (t *staticInvalid) Underlying() Type          { return underlying(t) }
+func (t *staticInvalid) Size() uintptr             { return uintptr(t.sizes().sizeof(t)) }
+func (t *staticInvalid) Align() int                { return int(t.sizes().alignof(t)) }
+func (t *staticInvalid) FieldAlign() int           { return int(t.sizes().alignof(t)) }
+func (t *staticInvalid) Implements(u Type) bool    { return implementsType(t, u) }
+func (t *staticInvalid) AssignableTo(u Type) bool  { return assignableTo(t, u) }
+func (t *staticInvalid) ConvertibleTo(u Type) bool { return convertibleTo(t, u) }
+func (t *staticInvalid) Comparable() bool          { return isComparable(t) }
+func (t *staticInvalid) String() string            { return "invalid type" }
 
 // -- staticType
 
+// pkgInfo identifies the package in which a type was constructed
+type pkgInfo struct {
+	name  string // package name, as in the package clause
+	path  string // import path
+	sizes *sizes // layout rules for the architecture the package was loaded for
+}
+
 type staticType struct {
-	name string
-	pkg  string
+	name       string
+	pkg        *pkgInfo           // package in which a named type is declared
+	methods    []*method          // methods declared with this receiver type, sorted by name
+	typeParams []*staticTypeParam // type parameters of a generic declaration
 }
 
-func newStaticType(name, pkg string) staticType {
+func newStaticType(name string, pkg *pkgInfo) staticType {
 	return staticType{
 		name: name,
 		pkg:  pkg,
@@ -114,45 +673,68 @@ func newStaticType(name, pkg string) staticType {
 
 func (t *staticType) common() {}
 
+// qualifiedName formats a named type as "pkg.Name", as reflect does
+func (t *staticType) qualifiedName() string {
+	if t.pkg == nil {
+		return t.name
+	}
+	return t.pkg.name + "." + t.name
+}
+
+func (t *staticType) base() *staticType { return t }
+
+// sizes returns the layout rules for the architecture that the type was
+// loaded for, or for the host architecture if the type was not loaded
+func (t *staticType) sizes() *sizes {
+	if t.pkg == nil || t.pkg.sizes == nil {
+		return hostSizes
+	}
+	return t.pkg.sizes
+}
+
 // Align returns the alignment in bytes of a value of
 // this type when allocated in memory.
 func (t *staticType) Align() int {
-	panic("Align not implemented")
+	panic("Align of incomplete type")
 }
 
 // FieldAlign returns the alignment in bytes of a value of
 // this type when used as a field in a struct.
 func (t *staticType) FieldAlign() int {
-	panic("FieldAlign not implemented")
+	panic("FieldAlign of incomplete type")
 }
 
 // Method returns the i'th method in the type's method set.
 // It panics if i is not in the range [0, NumMethod()).
 //
-// For a non-interface type T or *T, the returned Method's Type and Func
-// fields describe a function whose first argument is the receiver.
+// For a non-interface type T or *T, the returned Method's Type field
+// describes a function whose first argument is the receiver.
 //
 // For an interface type, the returned Method's Type field gives the
-// method signature, without a receiver, and the Func field is nil.
-func (t *staticType) Method(int) reflect.Method {
-	panic("Method not implemented")
+// method signature, without a receiver.
+func (t *staticType) Method(i int) Method {
+	m := exportedMethods(t.methods, false)[i]
+	return m.export(i, m.recv)
 }
 
 // MethodByName returns the method with that name in the type's
 // method set and a boolean indicating if the method was found.
 //
-// For a non-interface type T or *T, the returned Method's Type and Func
-// fields describe a function whose first argument is the receiver.
+// For a non-interface type T or *T, the returned Method's Type field
+// describes a function whose first argument is the receiver.
 //
 // For an interface type, the returned Method's Type field gives the
-// method signature, without a receiver, and the Func field is nil.
-func (t *staticType) MethodByName(string) (reflect.Method, bool) {
-	panic("MethodbyName not implemented")
+// method signature, without a receiver.
+func (t *staticType) MethodByName(name string) (Method, bool) {
+	if i, m := findMethod(exportedMethods(t.methods, false), name); m != nil {
+		return m.export(i, m.recv), true
+	}
+	return Method{}, false
 }
 
 // NumMethod returns the number of methods in the type's method set.
 func (t *staticType) NumMethod() int {
-	panic("NumMethod not implemented")
+	return len(exportedMethods(t.methods, false))
 }
 
 // Name returns the type's name within its package.
@@ -166,16 +748,34 @@ func (t *staticType) Name() string {
 // If the type was predeclared (string, error) or unnamed (*T, struct{}, []int),
 // the package path will be the empty string.
 func (t *staticType) PkgPath() string {
-	if t.name == "" {
+	if t.name == "" || t.pkg == nil {
 		return ""
 	}
-	return t.pkg + "/" + t.name
+	return t.pkg.path
+}
+
+// NumTypeParam returns the number of type parameters of a generic type
+// declaration. It returns zero for types that are not generic.
+func (t *staticType) NumTypeParam() int {
+	return len(t.typeParams)
+}
+
+// TypeParam returns the i'th type parameter of a generic type declaration.
+// It panics if i is not in the range [0, NumTypeParam()).
+func (t *staticType) TypeParam(i int) TypeParam {
+	p := t.typeParams[i]
+	return TypeParam{
+		Name:       p.name,
+		Index:      p.index,
+		Constraint: p.constraint,
+		Type:       p,
+	}
 }
 
 // Size returns the number of bytes needed to store
 // a value of the given type; it is analogous to unsafe.Sizeof.
 func (t *staticType) Size() uintptr {
-	panic("Size not implemented")
+	panic("Size of incomplete type")
 }
 
 // String returns a string representation of the type.
@@ -194,22 +794,22 @@ func (t *staticType) Kind() reflect.Kind {
 
 // Implements reports whether the type implements the interface type u.
 func (t *staticType) Implements(u Type) bool {
-	panic("Implements not implemented")
+	panic("Implements of incomplete type")
 }
 
 // AssignableTo reports whether a value of the type is assignable to type u.
 func (t *staticType) AssignableTo(u Type) bool {
-	panic("AssignableTo not implemented")
+	panic("AssignableTo of incomplete type")
 }
 
 // ConvertibleTo reports whether a value of the type is convertible to type u.
 func (t *staticType) ConvertibleTo(u Type) bool {
-	panic("ConvertibleTo not implemented")
+	panic("ConvertibleTo of incomplete type")
 }
 
 // Comparable reports whether values of this type are comparable.
 func (t *staticType) Comparable() bool {
-	panic("Comparable not implemented")
+	panic("Comparable of incomplete type")
 }
 
 // Bits returns the size of the type in bits.
@@ -251,7 +851,7 @@ func (t *staticType) Elem() Type {
 // It panics if the type's Kind is not Struct.
 // It panics if i is not in the range [0, NumField()).
 func (t *staticType) Field(i int) StructField {
-	panic("Field not implemented")
+	panic("Field of non-struct type")
 }
 
 // FieldByIndex returns the nested field corresponding
@@ -259,20 +859,57 @@ func (t *staticType) Field(i int) StructField {
 // successively for each index i.
 // It panics if the type's Kind is not Struct.
 func (t *staticType) FieldByIndex(index []int) StructField {
-	panic("FieldByIndex not implemented")
+	panic("FieldByIndex of non-struct type")
 }
 
 // FieldByName returns the struct field with the given name
 // and a boolean indicating if the field was found.
 func (t *staticType) FieldByName(name string) (StructField, bool) {
-	panic("FieldByName not implemented")
+	panic("FieldByName of non-struct type")
 }
 
 // FieldByNameFunc returns the first struct field with a name
 // that satisfies the match function and a boolean indicating if
 // the field was found.
 func (t *staticType) FieldByNameFunc(match func(string) bool) (StructField, bool) {
-	panic("FieldByNameFunc not implemented")
+	panic("FieldByNameFunc of non-struct type")
+}
+
+// IsMethodSet reports whether an interface type is fully described by its
+// method set, which means it can be used as an ordinary type rather than
+// only as a type constraint.
+// It panics if the type's Kind is not Interface.
+func (t *staticType) IsMethodSet() bool {
+	panic("IsMethodSet of non-interface type")
+}
+
+// NumTerm returns the number of terms in the type set of an interface.
+// It returns zero if the type set is not restricted by type terms or if
+// the type set is empty, which IsEmptyTypeSet distinguishes.
+// It panics if the type's Kind is not Interface.
+func (t *staticType) NumTerm() int {
+	panic("NumTerm of non-interface type")
+}
+
+// IsEmptyTypeSet reports whether the type set of an interface is empty,
+// as for "interface{ int; string }", in which case no type satisfies it.
+// It panics if the type's Kind is not Interface.
+func (t *staticType) IsEmptyTypeSet() bool {
+	panic("IsEmptyTypeSet of non-interface type")
+}
+
+// Term returns the i'th term in the type set of an interface.
+// It panics if the type's Kind is not Interface.
+// It panics if i is not in the range [0, NumTerm()).
+func (t *staticType) Term(i int) Term {
+	panic("Term of non-interface type")
+}
+
+// Satisfies reports whether type u satisfies an interface type when the
+// interface is used as a type constraint.
+// It panics if the type's Kind is not Interface.
+func (t *staticType) Satisfies(u Type) bool {
+	panic("Satisfies of non-interface type")
 }
 
 // In returns the type of a function type's i'th input parameter.
@@ -0,0 +1,27 @@
+package test
+
+import "time"
+
+type Human = Person
+
+type Employee Person
+
+type Contacts = map[string]*Person
+
+type Timestamp = time.Time
+
+type Moment time.Time
+
+type Celsius float64
+
+func (c Celsius) String() string {
+	return "celsius"
+}
+
+type Temperature = Celsius
+
+func (t Temperature) Kelvin() float64 {
+	return float64(t) + 273.15
+}
+
+type Reading Celsius
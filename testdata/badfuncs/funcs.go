@@ -0,0 +1,20 @@
+package badfuncs
+
+import "example.com/missing"
+
+type T struct {
+	A int
+}
+
+// functions whose signatures cannot be resolved
+func H(x missing.Thing) {}
+
+func H2(x missing.Other) int { return 0 }
+
+func Index(m map[[]byte]int, key Missing) {}
+
+func Keys(m map[string][]Missing) []string { return nil }
+
+func New() *T { return &T{} }
+
+func Bad(m map[[]byte]int) {}
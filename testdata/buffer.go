@@ -0,0 +1,43 @@
+package test
+
+import (
+	"crypto/sha256"
+	"unsafe"
+)
+
+const (
+	KB = 1 << (10 * (iota + 1))
+	MB
+)
+
+const BlockSize = 0x10
+
+type Size uint16
+
+const (
+	Small Size = 1 << iota
+	Medium
+	Large
+)
+
+var primes = [...]int{2, 3, 5, 7}
+
+var table [BlockSize / 4]int32
+
+type Header struct {
+	ID   uint32
+	Kind uint8
+}
+
+type Buffer struct {
+	Header [BlockSize]byte
+	Digest [sha256.Size]byte
+	Pages  [2 * KB / 1_000]uint8
+	Chunks [MB / KB >> 8]Header
+	Flags  [Large >> 1]bool
+	Primes [len(primes)]int
+	Table  [len(table)]bool
+	Name   [len("mold")]rune
+	Raw    [unsafe.Sizeof(Header{})]byte
+	Octal  [0o10 + 'a' - 'a']byte
+}
@@ -0,0 +1,32 @@
+package builtins
+
+import (
+	"unsafe"
+
+	"example.com/missing"
+)
+
+type S struct {
+	a bool
+	b int64
+}
+
+// constants that call builtins which the evaluator does not support
+const (
+	C = complex(1, 2)
+	M = max(1, 2)
+	O = unsafe.Offsetof(S{}.b)
+	P = M + 1
+)
+
+// constants of types that do not exist
+const (
+	D Undef        = 1
+	K missing.Kind = 3
+)
+
+const N = len("abc")
+
+type T struct {
+	A [N]int
+}
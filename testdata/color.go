@@ -0,0 +1,28 @@
+package test
+
+type Color int
+
+const (
+	// Red is the color of blood
+	Red Color = iota + 1
+	Green
+	Blue // a trailing comment is not documentation
+)
+
+type Weekday uint8
+
+const (
+	_ Weekday = iota
+	Monday
+	Tuesday
+)
+
+// Greeting is the default greeting
+const Greeting = "hello"
+
+const (
+	Pi  = 3.14159
+	Tau = 2 * Pi
+
+	Origin Color = 0
+)
@@ -0,0 +1,22 @@
+package test
+
+// Coord can be used as a map key
+type Coord struct {
+	X, Y  int
+	Label Label
+	Next  *Coord
+}
+
+type Board [3][3]Coord
+
+type Shelf [2]struct {
+	Name string
+	Tags map[string]bool
+}
+
+type Callback struct {
+	Coord
+	Run func()
+}
+
+type Grid[T ~int | ~[]int] [4]T
@@ -0,0 +1,37 @@
+package test
+
+import "time"
+
+type Number interface {
+	~int | ~int64 | ~float64
+}
+
+type Integer interface {
+	Number
+	~int | ~int64 | ~uint
+}
+
+type Stringish interface {
+	~string
+	String() string
+}
+
+type Key interface {
+	comparable
+}
+
+type Label string
+
+func (l Label) String() string {
+	return string(l)
+}
+
+type Stats[T Number] struct {
+	Min, Max T
+}
+
+type Series[T ~int | ~float64] []T
+
+type Window struct {
+	Span time.Duration
+}
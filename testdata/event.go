@@ -0,0 +1,12 @@
+package test
+
+import (
+	"net/http"
+	"time"
+)
+
+type Event struct {
+	When    time.Time
+	Header  http.Header
+	Timeout time.Duration
+}
@@ -0,0 +1,6 @@
+package test
+
+type Family struct {
+	Members []Person
+	Events  []Event
+}
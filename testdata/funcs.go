@@ -0,0 +1,29 @@
+package test
+
+import "context"
+
+// NewPerson creates a person with the given name
+func NewPerson(name string, age int) *Person {
+	return &Person{Name: name, Age: age}
+}
+
+// Greet greets each person in turn
+func Greet(ctx context.Context, greeting string, people ...Person) (n int, err error) {
+	return len(people), nil
+}
+
+func Max[T Number](xs ...T) T {
+	var max T
+	for _, x := range xs {
+		if x > max {
+			max = x
+		}
+	}
+	return max
+}
+
+func Lookup(map[Color]string, Color) (string, bool) {
+	return "", false
+}
+
+func init() {}
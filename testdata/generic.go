@@ -0,0 +1,45 @@
+package test
+
+import "fmt"
+
+type Page[T any] struct {
+	Items []T
+	Total int
+}
+
+func (p *Page[E]) Add(item E) {
+	p.Items = append(p.Items, item)
+}
+
+func (p Page[_]) Len() int {
+	return len(p.Items)
+}
+
+type Pair[K comparable, V fmt.Stringer] struct {
+	Key   K
+	Value V
+}
+
+type List[T any] []T
+
+type Outcome[T any, E error] struct {
+	Value T
+	Err   E
+}
+
+type Node[T any] struct {
+	Value T
+	Next  *Node[T]
+}
+
+type User struct {
+	Name string
+}
+
+type Cache struct {
+	Items   Page[User]
+	Results map[string]Outcome[int, error]
+	Recent  Page[User]
+	Users   List[*User]
+	Root    *Node[int]
+}
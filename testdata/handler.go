@@ -0,0 +1,14 @@
+package test
+
+import "context"
+
+type Handler func(ctx context.Context, args ...string) error
+
+type Middleware func(Handler) Handler
+
+type Route struct {
+	Path    string
+	Handle  Handler
+	Resolve func(name string, n int) (*Person, bool)
+	Filter  func(string, ...int) (bool, error)
+}
@@ -0,0 +1,12 @@
+package handlers
+
+import "context"
+
+type Handler func(ctx context.Context, args ...string) error
+
+type Middleware func(Handler) Handler
+
+type Chain struct {
+	Handlers []Handler
+	Wrap     func(h Handler, name string) (Handler, bool)
+}
@@ -0,0 +1,17 @@
+package test
+
+func (p Person) FullName() string {
+	return p.Name
+}
+
+func (p *Person) SetName(name string) {
+	p.Name = name
+}
+
+func (p Person) greet() string {
+	return "hello " + p.Name
+}
+
+func (p *Person) Adopt(children ...PersonPtr) {
+	p.Children = append(p.Children, children...)
+}
@@ -0,0 +1,16 @@
+package test
+
+type Result struct {
+	Value int
+	Err   error
+}
+
+type Pipeline struct {
+	Events  chan<- Event
+	Results <-chan Result
+	Done    chan struct{}
+	Nested  chan (<-chan int)
+	Feeds   chan Feed
+}
+
+type Feed <-chan Event
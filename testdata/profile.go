@@ -0,0 +1,16 @@
+package test
+
+type Profile struct {
+	Person
+	Handle  string `json:"handle"`
+	Address struct {
+		Street string `json:"street,omitempty"`
+		City   string
+	}
+	Maker interface {
+		Make() Person
+		close() error
+	}
+	Empty struct{}
+	Any   interface{}
+}
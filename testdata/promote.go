@@ -0,0 +1,58 @@
+package test
+
+type Base struct {
+	ID   int
+	Name string
+}
+
+type Audit struct {
+	ID int
+	By string
+}
+
+type Document struct {
+	Base
+	*Audit
+	Title string
+}
+
+type Archive struct {
+	Document
+	Name string
+	Twice
+}
+
+type Twice struct {
+	Base
+}
+
+func (b Base) Describe() string {
+	return b.Name
+}
+
+func (b *Base) Rename(name string) {
+	b.Name = name
+}
+
+func (a Audit) Describe() string {
+	return a.By
+}
+
+func (a *Audit) Sign() string {
+	return a.By
+}
+
+type Logger interface {
+	Log(msg string)
+}
+
+type Describer interface {
+	Describe() string
+}
+
+type Service struct {
+	Logger
+	*Base
+}
+
+type Renamed Service
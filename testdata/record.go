@@ -0,0 +1,18 @@
+package test
+
+type Record struct {
+	*Event
+	Flag   bool
+	ID     int64
+	Name   string
+	small  int8
+	Values []float32
+	Tags   map[string]string
+	Ratio  complex128
+	Nested struct {
+		A byte
+		B int32
+	}
+	Arr   [3]int16
+	Empty struct{}
+}
@@ -0,0 +1,7 @@
+package redeclared
+
+type A int
+
+const Max = 10
+
+func Open() {}
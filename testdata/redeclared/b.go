@@ -0,0 +1,11 @@
+package redeclared
+
+type A string
+
+const Max = 20
+
+var Open = 1
+
+type B struct {
+	A A
+}
@@ -0,0 +1,16 @@
+package test
+
+import "io"
+
+type Store interface {
+	io.ReadWriter
+	io.Closer
+	Get(key string) (*Person, error)
+	Put(key string, p *Person) error
+	close() error
+}
+
+type Closer interface {
+	io.Closer
+	Close() error
+}
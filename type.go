@@ -1,6 +1,7 @@
 package mold
 
 import (
+	"go/token"
 	"reflect"
 	"strconv"
 )
@@ -26,22 +27,22 @@ type Type interface {
 	// Method returns the i'th method in the type's method set.
 	// It panics if i is not in the range [0, NumMethod()).
 	//
-	// For a non-interface type T or *T, the returned Method's Type and Func
-	// fields describe a function whose first argument is the receiver.
+	// For a non-interface type T or *T, the returned Method's Type field
+	// describes a function whose first argument is the receiver.
 	//
 	// For an interface type, the returned Method's Type field gives the
-	// method signature, without a receiver, and the Func field is nil.
-	Method(int) reflect.Method
+	// method signature, without a receiver.
+	Method(int) Method
 
 	// MethodByName returns the method with that name in the type's
 	// method set and a boolean indicating if the method was found.
 	//
-	// For a non-interface type T or *T, the returned Method's Type and Func
-	// fields describe a function whose first argument is the receiver.
+	// For a non-interface type T or *T, the returned Method's Type field
+	// describes a function whose first argument is the receiver.
 	//
 	// For an interface type, the returned Method's Type field gives the
-	// method signature, without a receiver, and the Func field is nil.
-	MethodByName(string) (reflect.Method, bool)
+	// method signature, without a receiver.
+	MethodByName(string) (Method, bool)
 
 	// NumMethod returns the number of methods in the type's method set.
 	NumMethod() int
@@ -60,6 +61,14 @@ type Type interface {
 	// a value of the given type; it is analogous to unsafe.Sizeof.
 	Size() uintptr
 
+	// NumTypeParam returns the number of type parameters of a generic type
+	// declaration. It returns zero for types that are not generic.
+	NumTypeParam() int
+
+	// TypeParam returns the i'th type parameter of a generic type declaration.
+	// It panics if i is not in the range [0, NumTypeParam()).
+	TypeParam(i int) TypeParam
+
 	// String returns a string representation of the type.
 	// The string representation may use shortened package names
 	// (e.g., base64 instead of "encoding/base64") and is not
@@ -70,6 +79,12 @@ type Type interface {
 	// Kind returns the specific kind of this type.
 	Kind() reflect.Kind
 
+	// Underlying returns the underlying type of this type. For a named type
+	// this is the unnamed type that it was declared in terms of, without any
+	// methods, and for an unnamed type it is the type itself. For a type
+	// parameter it is the underlying type of its constraint.
+	Underlying() Type
+
 	// Implements reports whether the type implements the interface type u.
 	Implements(u Type) bool
 
@@ -89,6 +104,7 @@ type Type interface {
 	//	Array: Elem, Len
 	//	Chan: ChanDir, Elem
 	//	Func: In, NumIn, Out, NumOut, IsVariadic.
+	//	Interface: IsMethodSet, NumTerm, Term, IsEmptyTypeSet, Satisfies
 	//	Map: Key, Elem
 	//	Ptr: Elem
 	//	Slice: Elem
@@ -146,6 +162,33 @@ type Type interface {
 	// It panics if i is not in the range [0, NumIn()).
 	In(i int) Type
 
+	// IsMethodSet reports whether an interface type is fully described by its
+	// method set, which means it can be used as an ordinary type rather than
+	// only as a type constraint.
+	// It panics if the type's Kind is not Interface.
+	IsMethodSet() bool
+
+	// NumTerm returns the number of terms in the type set of an interface.
+	// It returns zero if the type set is not restricted by type terms or if
+	// the type set is empty, which IsEmptyTypeSet distinguishes.
+	// It panics if the type's Kind is not Interface.
+	NumTerm() int
+
+	// IsEmptyTypeSet reports whether the type set of an interface is empty,
+	// as for "interface{ int; string }", in which case no type satisfies it.
+	// It panics if the type's Kind is not Interface.
+	IsEmptyTypeSet() bool
+
+	// Term returns the i'th term in the type set of an interface.
+	// It panics if the type's Kind is not Interface.
+	// It panics if i is not in the range [0, NumTerm()).
+	Term(i int) Term
+
+	// Satisfies reports whether type u satisfies an interface type when the
+	// interface is used as a type constraint.
+	// It panics if the type's Kind is not Interface.
+	Satisfies(u Type) bool
+
 	// Key returns a map type's key type.
 	// It panics if the type's Kind is not Map.
 	Key() Type
@@ -188,6 +231,44 @@ type StructField struct {
 	Anonymous bool      // is an embedded field
 }
 
+// A TypeParam describes a type parameter of a generic type declaration.
+type TypeParam struct {
+	Name       string // parameter name, such as "T"
+	Index      int    // index for Type.TypeParam
+	Constraint Type   // constraint, such as "any" or "comparable"
+
+	// Type is the type parameter itself, as it is referred to within the
+	// body of the declaration. For example, in "type Page[T any] []T", the
+	// element type of the slice is the Type field of the type parameter.
+	Type Type
+}
+
+// A Term is an element of the type set of a constraint interface, such
+// as "int" or "~string".
+type Term struct {
+	Tilde bool // whether the term stands for all types with underlying type Type
+	Type  Type
+}
+
+// Method represents a single method.
+//
+// For types loaded from source, PointerReceiver reports whether the method
+// was declared with a pointer receiver. Since reflect does not record
+// receivers, for a live pointer type *T it reports whether the method is in
+// the method set of *T but not of T.
+type Method struct {
+	// Name is the method name.
+	Name string
+	// PkgPath is the package path that qualifies a lower case (unexported)
+	// method name.  It is empty for upper case (exported) method names.
+	PkgPath string
+
+	Type            Type           // method type
+	Index           int            // index for Type.Method
+	PointerReceiver bool           // whether the method was declared with a pointer receiver, see below
+	Pos             token.Position // position of the method declaration, if loaded from source
+}
+
 // A StructTag is the tag string in a struct field.
 //
 // By convention, tag strings are a concatenation of
@@ -0,0 +1,287 @@
+package mold
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// typeSet returns the terms of an interface type. It reports false if the
+// type set is not restricted by terms, in which case it contains every type
+// that implements the methods of the interface.
+func typeSet(t Type) (terms []Term, restricted bool) {
+	switch t := t.(type) {
+	case *staticInterface:
+		return t.terms, t.restricted
+	case *staticDefined:
+		return typeSet(t.Type)
+	case *staticTypeParam:
+		return typeSet(t.constraint)
+	}
+	return nil, false
+}
+
+// isComparableConstraint reports whether an interface type restricts its
+// type set to comparable types, either by being or embedding "comparable"
+func isComparableConstraint(t Type) bool {
+	switch t := t.(type) {
+	case *staticInterface:
+		return t.comparable
+	case *staticDefined:
+		return isComparableConstraint(t.Type)
+	case *staticTypeParam:
+		return isComparableConstraint(t.constraint)
+	}
+	return false
+}
+
+// intersectTerms computes the terms of the intersection of two type sets
+func intersectTerms(x, y []Term) []Term {
+	var terms []Term
+	add := func(term Term) {
+		for _, prev := range terms {
+			if prev.Tilde == term.Tilde && identical(prev.Type, term.Type) {
+				return
+			}
+		}
+		terms = append(terms, term)
+	}
+	for _, a := range x {
+		for _, b := range y {
+			switch {
+			case a.Tilde && b.Tilde:
+				if identical(a.Type, b.Type) {
+					add(a)
+				}
+			case a.Tilde:
+				if identical(underlying(b.Type), a.Type) {
+					add(b)
+				}
+			case b.Tilde:
+				if identical(underlying(a.Type), b.Type) {
+					add(a)
+				}
+			default:
+				if identical(a.Type, b.Type) {
+					add(a)
+				}
+			}
+		}
+	}
+	return terms
+}
+
+// includes reports whether a term includes type t
+func (term Term) includes(t Type) bool {
+	if term.Tilde {
+		return identical(underlying(t), term.Type)
+	}
+	return identical(t, term.Type)
+}
+
+// underlying returns the underlying type of t. For named types declared
+// from source this is an unnamed copy of the type without its methods.
+// Named types must be complete before calling underlying.
+func underlying(t Type) Type {
+	switch u := t.(type) {
+	case *staticDefined:
+		return underlying(u.Type)
+	case liveType:
+		return liveUnderlying(u.Type)
+	case *staticTypeParam:
+		return underlying(u.constraint)
+	case *staticInvalid:
+		return t
+	case interface{ base() *staticType }:
+		if t.Name() == "" {
+			return t
+		}
+		switch u := t.(type) {
+		case *staticPtr:
+			cp := *u
+			cp.staticType = staticType{pkg: u.pkg}
+			return &cp
+		case *staticArray:
+			cp := *u
+			cp.staticType = staticType{pkg: u.pkg}
+			return &cp
+		case *staticSlice:
+			cp := *u
+			cp.staticType = staticType{pkg: u.pkg}
+			return &cp
+		case *staticMap:
+			cp := *u
+			cp.staticType = staticType{pkg: u.pkg}
+			return &cp
+		case *staticChan:
+			cp := *u
+			cp.staticType = staticType{pkg: u.pkg}
+			return &cp
+		case *staticFunc:
+			cp := *u
+			cp.staticType = staticType{pkg: u.pkg}
+			return &cp
+		case *staticStruct:
+			cp := *u
+			cp.staticType = staticType{pkg: u.pkg}
+			return &cp
+		case *staticInterface:
+			cp := *u
+			cp.staticType = staticType{pkg: u.pkg}
+			return &cp
+		}
+	}
+	return t
+}
+
+// basicTypes holds the predeclared type for each basic kind
+var basicTypes = map[reflect.Kind]reflect.Type{
+	reflect.Bool:          reflect.TypeOf(false),
+	reflect.Int:           reflect.TypeOf(int(0)),
+	reflect.Int8:          reflect.TypeOf(int8(0)),
+	reflect.Int16:         reflect.TypeOf(int16(0)),
+	reflect.Int32:         reflect.TypeOf(int32(0)),
+	reflect.Int64:         reflect.TypeOf(int64(0)),
+	reflect.Uint:          reflect.TypeOf(uint(0)),
+	reflect.Uint8:         reflect.TypeOf(uint8(0)),
+	reflect.Uint16:        reflect.TypeOf(uint16(0)),
+	reflect.Uint32:        reflect.TypeOf(uint32(0)),
+	reflect.Uint64:        reflect.TypeOf(uint64(0)),
+	reflect.Uintptr:       reflect.TypeOf(uintptr(0)),
+	reflect.Float32:       reflect.TypeOf(float32(0)),
+	reflect.Float64:       reflect.TypeOf(float64(0)),
+	reflect.Complex64:     reflect.TypeOf(complex64(0)),
+	reflect.Complex128:    reflect.TypeOf(complex128(0)),
+	reflect.String:        reflect.TypeOf(""),
+	reflect.UnsafePointer: reflect.TypeOf(unsafe.Pointer(nil)),
+}
+
+// liveUnderlying returns the underlying type of a live type. Named struct
+// and interface types are returned unchanged since reflect cannot construct
+// their unnamed equivalents in general.
+func liveUnderlying(t reflect.Type) Type {
+	if t.Name() == "" {
+		return liveType{t}
+	}
+	if u, found := basicTypes[t.Kind()]; found {
+		return liveType{u}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return liveType{reflect.PointerTo(t.Elem())}
+	case reflect.Array:
+		return liveType{reflect.ArrayOf(t.Len(), t.Elem())}
+	case reflect.Slice:
+		return liveType{reflect.SliceOf(t.Elem())}
+	case reflect.Map:
+		return liveType{reflect.MapOf(t.Key(), t.Elem())}
+	case reflect.Chan:
+		return liveType{reflect.ChanOf(t.ChanDir(), t.Elem())}
+	case reflect.Func:
+		var in, out []reflect.Type
+		for i := 0; i < t.NumIn(); i++ {
+			in = append(in, t.In(i))
+		}
+		for i := 0; i < t.NumOut(); i++ {
+			out = append(out, t.Out(i))
+		}
+		return liveType{reflect.FuncOf(in, out, t.IsVariadic())}
+	}
+	return liveType{t}
+}
+
+// methodSet returns the method set of t, including unexported methods
+func methodSet(t Type) []*method {
+	switch u := t.(type) {
+	case liveType:
+		return liveMethods(u.Type)
+	case *staticPtr:
+		if u.name != "" {
+			return nil
+		}
+		if elem, ok := u.elem.(liveType); ok {
+			return liveMethods(reflect.PointerTo(elem.Type))
+		}
+		if k := u.elem.Kind(); k == reflect.Interface || k == reflect.Ptr {
+			return nil
+		}
+		return promotedMethods(u.elem, true)
+	}
+	if t.Kind() == reflect.Interface {
+		return interfaceMethods(t)
+	}
+	return promotedMethods(t, false)
+}
+
+// implements reports whether the method set of t contains every method of
+// the interface type iface
+func implements(t, iface Type) bool {
+	methods := methodSet(t)
+	for _, m := range interfaceMethods(iface) {
+		found := false
+		for _, n := range methods {
+			if n.name == m.name && n.pkgPath == m.pkgPath {
+				found = identical(n.signature(), m.signature())
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// satisfies reports whether t satisfies the constraint iface, which means
+// that t implements its methods, belongs to its type set and, if the
+// constraint is comparable, that t is comparable.
+func satisfies(t, iface Type) bool {
+	if t == invalidType || !implements(t, iface) {
+		return false
+	}
+	if isComparableConstraint(iface) && !isComparable(t) {
+		return false
+	}
+	terms, restricted := typeSet(iface)
+	if !restricted {
+		return true
+	}
+	if len(terms) == 0 {
+		return false // the type set is empty
+	}
+
+	// a type parameter satisfies the constraint if every type in its own
+	// type set does
+	if p, ok := t.(*staticTypeParam); ok {
+		pterms, prestricted := typeSet(p.constraint)
+		if !prestricted {
+			return false
+		}
+		for _, pt := range pterms {
+			if !includedBy(pt, terms) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, term := range terms {
+		if term.includes(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// includedBy reports whether every type in the set described by term is
+// also described by one of terms
+func includedBy(term Term, terms []Term) bool {
+	for _, u := range terms {
+		if term.Tilde && !u.Tilde {
+			continue
+		}
+		if u.includes(term.Type) {
+			return true
+		}
+	}
+	return false
+}
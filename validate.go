@@ -0,0 +1,287 @@
+package mold
+
+import (
+	"go/ast"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// validate checks the declarations in this package against the rules of
+// the language spec that can only be checked once every type has been
+// populated, such as whether map keys are comparable or whether a struct
+// contains itself
+func (b *builder) validate() {
+	var names []string
+	for name := range b.decls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	recursive := make(map[Type]bool) // types already reported as recursive
+	for _, name := range names {
+		decl := b.decls[name]
+		t, found := b.lookup(name)
+		if !found || t == invalidType {
+			continue
+		}
+
+		if isTypeParamExpr(decl.spec.Type, decl.spec.TypeParams) {
+			b.errorf(decl.spec.Type.Pos(), InvalidDeclaration, name,
+				"cannot use a type parameter as RHS in type declaration")
+			continue
+		}
+		b.validateExpr(decl.spec.Type, t)
+
+		if decl.spec.Assign.IsValid() {
+			continue // methods and recursion are checked at the target
+		}
+		if !recursive[t] {
+			if cycle := findCycle(t); cycle != nil {
+				var names []string
+				for _, c := range cycle {
+					names = append(names, c.Name())
+					recursive[c] = true
+				}
+				names = append(names, t.Name())
+				b.errorf(decl.spec.Name.Pos(), InvalidDeclaration, name,
+					"invalid recursive type %s (%s)", name, strings.Join(names, " refers to "))
+			}
+		}
+		b.validateMethods(decl, t)
+	}
+
+	// a method declared on the blank identifier has no type to belong to
+	for _, fd := range b.methods["_"] {
+		b.errorf(fd.decl.Recv.List[0].Type.Pos(), InvalidDeclaration, "_", "cannot use _ as a type")
+	}
+}
+
+// isTypeParamExpr reports whether expr is one of the type parameters declared
+// in fields, as in "type T[P any] P"
+func isTypeParamExpr(expr ast.Expr, fields *ast.FieldList) bool {
+	for paren, ok := expr.(*ast.ParenExpr); ok; paren, ok = expr.(*ast.ParenExpr) {
+		expr = paren.X
+	}
+	ident, ok := expr.(*ast.Ident)
+	if !ok || fields == nil {
+		return false
+	}
+	for _, f := range fields.List {
+		for _, name := range f.Names {
+			if name.Name == ident.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateType checks a type that is used as an ordinary type, such as
+// the type of a field or a parameter, where an interface that can only be
+// used as a type constraint is not allowed
+func (b *builder) validateType(expr ast.Expr, t Type) {
+	if t != invalidType && t.Kind() == reflect.Interface && !isTypeParam(t) && !t.IsMethodSet() {
+		reason := "interface contains type constraints"
+		if _, restricted := typeSet(t); !restricted {
+			reason = "interface is (or embeds) comparable"
+		}
+		b.errorf(expr.Pos(), InvalidDeclaration, t.Name(),
+			"cannot use type %s outside a type constraint: %s", t, reason)
+		return
+	}
+	b.validateExpr(expr, t)
+}
+
+// validateExpr checks the type t constructed from expr, walking the
+// expression and the type together so that problems can be reported at
+// the position of the sub-expression responsible. Named types are checked
+// at their own declarations.
+func (b *builder) validateExpr(expr ast.Expr, t Type) {
+	if t == invalidType || t.Kind() == reflect.Invalid {
+		return // already reported
+	}
+	switch expr := expr.(type) {
+	case *ast.ParenExpr:
+		b.validateExpr(expr.X, t)
+	case *ast.StarExpr:
+		b.validateType(expr.X, t.Elem())
+	case *ast.Ellipsis:
+		b.validateType(expr.Elt, t.Elem())
+	case *ast.ArrayType:
+		if t.Kind() == reflect.Array {
+			s := b.pkg.sizes
+			if n, size := int64(t.Len()), s.sizeof(t.Elem()); size > 0 && n > s.maxInt()/size {
+				b.errorf(expr.Pos(), InvalidArrayLength, "", "array too large")
+			}
+		}
+		b.validateType(expr.Elt, t.Elem())
+	case *ast.ChanType:
+		b.validateType(expr.Value, t.Elem())
+	case *ast.MapType:
+		key := t.Key()
+		if key != invalidType && !isComparable(key) {
+			switch key.Kind() {
+			case reflect.Struct, reflect.Array:
+				b.errorf(expr.Key.Pos(), InvalidDeclaration, key.Name(),
+					"invalid map key type %s (%v)", key, ComparableError(key))
+			default:
+				b.errorf(expr.Key.Pos(), InvalidDeclaration, key.Name(),
+					"invalid map key type %s", key)
+			}
+		}
+		b.validateType(expr.Key, key)
+		b.validateType(expr.Value, t.Elem())
+	case *ast.StructType:
+		b.validateStruct(expr, t)
+	case *ast.FuncType:
+		b.validateParams(expr.Params, t.In)
+		b.validateParams(expr.Results, t.Out)
+	case *ast.InterfaceType:
+		methods := interfaceMethods(t)
+		for _, f := range expr.Methods.List {
+			for _, ident := range f.Names {
+				for _, m := range methods {
+					if m.name == ident.Name {
+						b.validateExpr(f.Type, m.signature())
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+// validateStruct checks that the field names of a struct are unique and
+// validates the type of each field
+func (b *builder) validateStruct(expr *ast.StructType, t Type) {
+	seen := make(map[string]bool)
+	i := 0
+	for _, f := range expr.Fields.List {
+		idents := f.Names
+		if idents == nil {
+			// anonymous field
+			idents = []*ast.Ident{{NamePos: f.Type.Pos(), Name: embeddedName(f.Type)}}
+		}
+		for _, ident := range idents {
+			if ident.Name != "_" {
+				if seen[ident.Name] {
+					b.errorf(ident.Pos(), InvalidDeclaration, ident.Name,
+						"duplicate field %s", ident.Name)
+				}
+				seen[ident.Name] = true
+			}
+			if i < t.NumField() {
+				b.validateType(f.Type, t.Field(i).Type)
+			}
+			i++
+		}
+	}
+}
+
+// validateParams validates the parameter types of a function, where param
+// is either In or Out of the function type
+func (b *builder) validateParams(fields *ast.FieldList, param func(int) Type) {
+	if fields == nil {
+		return
+	}
+	i := 0
+	for _, f := range fields.List {
+		n := len(f.Names)
+		if n == 0 {
+			n = 1 // unnamed parameter
+		}
+		for j := 0; j < n; j++ {
+			b.validateType(f.Type, param(i))
+			i++
+		}
+	}
+}
+
+// validateMethods checks that the methods declared on a type have distinct
+// names that do not collide with its fields, and validates their signatures
+func (b *builder) validateMethods(decl *typeDecl, t Type) {
+	named, ok := t.(interface{ base() *staticType })
+	if !ok {
+		return
+	}
+	methods := named.base().methods
+
+	fields := make(map[string]bool)
+	if t.Kind() == reflect.Struct {
+		for i := 0; i < t.NumField(); i++ {
+			fields[t.Field(i).Name] = true
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range b.receiverNames(decl.spec.Name.Name) {
+		for _, fd := range b.methods[name] {
+			ident := fd.decl.Name
+			if ident.Name == "_" {
+				continue
+			}
+			switch {
+			case seen[ident.Name]:
+				b.errorf(ident.Pos(), InvalidDeclaration, ident.Name,
+					"method %s.%s already declared", decl.spec.Name.Name, ident.Name)
+			case fields[ident.Name]:
+				b.errorf(ident.Pos(), InvalidDeclaration, ident.Name,
+					"field and method with the same name %s", ident.Name)
+			}
+			seen[ident.Name] = true
+
+			pos := b.loader.fset.Position(ident.Pos())
+			for _, m := range methods {
+				if m.pos == pos {
+					b.validateExpr(fd.decl.Type, m.signature())
+					break
+				}
+			}
+		}
+	}
+}
+
+// findCycle finds a path of struct fields and array elements by which a
+// named type contains itself, such as "type T struct{ t T }", and returns
+// the named types along the path starting with t itself. It returns nil if
+// t does not contain itself.
+func findCycle(t Type) []Type {
+	var path []Type
+	visited := make(map[Type]bool)
+	var visit func(u Type) bool
+	visit = func(u Type) bool {
+		if u == t && len(path) > 0 {
+			return true
+		}
+		if _, live := u.(liveType); live || visited[u] {
+			return false // types that can reach t have already returned true
+		}
+		visited[u] = true
+
+		named := u.Name() != ""
+		if named {
+			path = append(path, u)
+		}
+		switch u.Kind() {
+		case reflect.Array:
+			if visit(u.Elem()) {
+				return true
+			}
+		case reflect.Struct:
+			for i := 0; i < u.NumField(); i++ {
+				if visit(u.Field(i).Type) {
+					return true
+				}
+			}
+		}
+		if named {
+			path = path[:len(path)-1]
+		}
+		return false
+	}
+	if visit(t) {
+		return path
+	}
+	return nil
+}